@@ -0,0 +1,231 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/frp256v1"
+	"github.com/pedroalbanese/go-nums"
+	"github.com/pedroalbanese/secp256k1"
+)
+
+// maxEmbeddingDegree is how far AnalyzeCurve searches for the embedding
+// degree. Pairing based (MOV/FR) attacks are only practical for small
+// degrees, so anything above it is reported as safe.
+const maxEmbeddingDegree = 100
+
+// twistTrialDivisionBound bounds the small prime factors stripped from the
+// twist order before testing the rest for primality.
+const twistTrialDivisionBound = 1 << 16
+
+// CurveAnalysis reports properties of a curve relevant to its
+// trustworthiness. It is informational; nothing in the package refuses a
+// curve because of it.
+type CurveAnalysis struct {
+	Name string
+
+	// Cofactor is h = round((P+1)/N), which is exact whenever N is larger
+	// than 4·sqrt(P), as for every registered prime curve.
+	Cofactor *big.Int
+
+	// Trace is the trace of Frobenius t = P + 1 - h·N.
+	Trace *big.Int
+
+	// Provenance describes how the parameters were published to have
+	// been generated, for the curves the package knows. It is taken from
+	// the specification, not re-derived: the seeds are not rehashed.
+	Provenance string
+
+	// EmbeddingDegree is the smallest k with P^k = 1 mod N, or 0 if there
+	// is none up to 100, which means the curve resists MOV/FR attacks.
+	EmbeddingDegree int
+
+	// Anomalous is set if N = P, which breaks the discrete logarithm with
+	// Smart's attack.
+	Anomalous bool
+
+	// CMDiscriminantBits is the bit length of |t² - 4P|. The CM
+	// discriminant is its square-free part, which would need factoring to
+	// compute, so this is an upper bound; curves with a tiny discriminant,
+	// like secp256k1 (D = -3), still show up as such only if the square
+	// factor is small.
+	CMDiscriminantBits int
+
+	// TwistOrder is P + 1 + t, the order of the quadratic twist.
+	TwistOrder *big.Int
+
+	// TwistSecurity is half the bit length of the largest prime factor
+	// of TwistOrder, or -1 if that factor could not be determined, that
+	// is when the order left after removing prime factors below 2^16 is
+	// composite.
+	TwistSecurity int
+
+	// Findings are human readable notes on the points above that deserve
+	// attention.
+	Findings []string
+}
+
+// curveProvenance lists the published generation method of known curves.
+var curveProvenance = []struct {
+	curve      elliptic.Curve
+	provenance string
+}{
+	{elliptic.P224(), "FIPS 186 / SEC 2: generated from a SHA-1 seed whose origin is unexplained"},
+	{elliptic.P256(), "FIPS 186 / SEC 2: generated from a SHA-1 seed whose origin is unexplained"},
+	{elliptic.P384(), "FIPS 186 / SEC 2: generated from a SHA-1 seed whose origin is unexplained"},
+	{elliptic.P521(), "FIPS 186 / SEC 2: generated from a SHA-1 seed whose origin is unexplained"},
+
+	{brainpool.P160r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P192r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P224r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P256r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P320r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P384r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P512r1(), "RFC 5639: verifiably pseudo-random, seeded from the digits of pi"},
+	{brainpool.P160t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P192t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P224t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P256t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P320t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P384t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+	{brainpool.P512t1(), "RFC 5639: isomorphic to the r1 curve, with a = -3"},
+
+	{secp256k1.S256(), "SEC 2 Koblitz curve: a = 0, b = 7, no seed"},
+	{frp256v1.P256(), "ANSSI FRP256v1: generation method not published"},
+
+	{nums.P256d1(), "NUMS: smallest parameters satisfying published criteria"},
+	{nums.P384d1(), "NUMS: smallest parameters satisfying published criteria"},
+	{nums.P512d1(), "NUMS: smallest parameters satisfying published criteria"},
+}
+
+// AnalyzeCurve computes a CurveAnalysis for a prime field curve in short
+// Weierstrass form. The checks are:
+//
+//   - the cofactor and the trace of Frobenius, from P and N;
+//   - the embedding degree, searched up to 100 (MOV/FR resistance);
+//   - whether the curve is anomalous (N = P);
+//   - an upper bound on the size of the CM discriminant;
+//   - the twist order and, as far as trial division allows, its largest
+//     prime factor (twist security);
+//   - the published provenance of the parameters for known curves.
+//
+// It cannot tell whether parameters hide a weakness that these checks do
+// not cover, and it does not re-run any seeded generation procedure.
+func AnalyzeCurve(curve elliptic.Curve) (*CurveAnalysis, error) {
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	params := curve.Params()
+	p, n := params.P, params.N
+
+	if !p.ProbablyPrime(20) {
+		return nil, errors.New("ecgdsa: curve analysis needs a prime field")
+	}
+
+	if !n.ProbablyPrime(20) {
+		return nil, errors.New("ecgdsa: curve order is not prime")
+	}
+
+	a := &CurveAnalysis{
+		Name:          params.Name,
+		Provenance:    "unknown",
+		TwistSecurity: -1,
+	}
+
+	for _, c := range curveProvenance {
+		if c.curve == curve {
+			a.Provenance = c.provenance
+		}
+	}
+
+	pPlus1 := new(big.Int).Add(p, big.NewInt(1))
+	h := estimateCofactor(params)
+	a.Cofactor = h
+
+	order := new(big.Int).Mul(h, n)
+	a.Trace = new(big.Int).Sub(pPlus1, order)
+
+	a.Anomalous = n.Cmp(p) == 0
+	if a.Anomalous {
+		a.Findings = append(a.Findings, "anomalous curve: N = P, vulnerable to Smart's attack")
+	}
+
+	pk := new(big.Int).Mod(p, n)
+	q := new(big.Int).Set(pk)
+	for k := 1; k <= maxEmbeddingDegree; k++ {
+		if q.Cmp(big.NewInt(1)) == 0 {
+			a.EmbeddingDegree = k
+			a.Findings = append(a.Findings, fmt.Sprintf("embedding degree %d: vulnerable to MOV/FR attacks", k))
+			break
+		}
+
+		q.Mul(q, pk)
+		q.Mod(q, n)
+	}
+
+	disc := new(big.Int).Mul(a.Trace, a.Trace)
+	disc.Sub(disc, new(big.Int).Lsh(p, 2))
+	a.CMDiscriminantBits = disc.BitLen()
+	if a.CMDiscriminantBits < 100 {
+		a.Findings = append(a.Findings, "small CM discriminant bound")
+	}
+
+	a.TwistOrder = new(big.Int).Add(pPlus1, a.Trace)
+	if largest := largestPrimeFactor(a.TwistOrder); largest != nil {
+		a.TwistSecurity = largest.BitLen() / 2
+		if a.TwistSecurity < SecurityLevel(curve) {
+			a.Findings = append(a.Findings, fmt.Sprintf("twist security is only about %d bits", a.TwistSecurity))
+		}
+	}
+
+	if h.Cmp(big.NewInt(1)) != 0 {
+		a.Findings = append(a.Findings, fmt.Sprintf("cofactor %s: points must be checked for subgroup membership", h))
+	}
+
+	return a, nil
+}
+
+// largestPrimeFactor strips the prime factors of m below the trial
+// division bound and returns the rest if it is prime, or the largest small
+// factor if nothing is left. It returns nil when the rest is composite.
+func largestPrimeFactor(m *big.Int) *big.Int {
+	rest := new(big.Int).Set(m)
+	largest := big.NewInt(1)
+
+	r := new(big.Int)
+	for f := int64(2); f < twistTrialDivisionBound; f++ {
+		bf := big.NewInt(f)
+		for {
+			q, _ := new(big.Int).QuoRem(rest, bf, r)
+			if r.Sign() != 0 {
+				break
+			}
+
+			rest = q
+			largest = bf
+		}
+	}
+
+	switch {
+	case rest.Cmp(big.NewInt(1)) == 0:
+		return largest
+	case rest.ProbablyPrime(20):
+		return rest
+	}
+
+	return nil
+}
+
+// estimateCofactor returns h = round((P+1)/N), which by the Hasse bound is
+// the cofactor whenever N > 4·sqrt(P).
+func estimateCofactor(params *elliptic.CurveParams) *big.Int {
+	h := new(big.Int).Add(params.P, big.NewInt(1))
+	h.Lsh(h, 1)
+	h.Add(h, params.N)
+
+	return h.Div(h, new(big.Int).Lsh(params.N, 1))
+}
@@ -0,0 +1,70 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestAnalyzeCurve(t *testing.T) {
+	for _, tt := range []struct {
+		curve         elliptic.Curve
+		provenance    string
+		traceBits     int
+		discBits      int
+		twistSecurity int
+		findings      int
+	}{
+		// The twist of P-256 has order 3·5·13·179·q with q a 241-bit prime,
+		// which is reported as below its 128-bit security level.
+		{elliptic.P256(), "SHA-1 seed", 127, 258, 120, 1},
+		// The twist of brainpoolP256r1 has no small factors to strip and a
+		// composite rest, so its security is not determined.
+		{brainpool.P256r1(), "digits of pi", 128, 257, -1, 0},
+	} {
+		name := tt.curve.Params().Name
+
+		a, err := AnalyzeCurve(tt.curve)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if a.Cofactor.Cmp(big.NewInt(1)) != 0 || a.Anomalous || a.EmbeddingDegree != 0 {
+			t.Errorf("%s: cofactor %s, anomalous %v, embedding degree %d", name, a.Cofactor, a.Anomalous, a.EmbeddingDegree)
+		}
+
+		if !strings.Contains(a.Provenance, tt.provenance) {
+			t.Errorf("%s: provenance %q", name, a.Provenance)
+		}
+
+		if a.Trace.BitLen() != tt.traceBits || a.CMDiscriminantBits != tt.discBits || a.TwistSecurity != tt.twistSecurity {
+			t.Errorf("%s: trace %d bits, discriminant %d bits, twist security %d", name, a.Trace.BitLen(), a.CMDiscriminantBits, a.TwistSecurity)
+		}
+
+		// P + 1 - t and P + 1 + t are the orders of the curve and its twist.
+		sum := new(big.Int).Add(new(big.Int).Mul(a.Cofactor, tt.curve.Params().N), a.TwistOrder)
+		if want := new(big.Int).Lsh(new(big.Int).Add(tt.curve.Params().P, big.NewInt(1)), 1); sum.Cmp(want) != 0 {
+			t.Errorf("%s: curve and twist orders do not add up to 2(P+1)", name)
+		}
+
+		if len(a.Findings) != tt.findings {
+			t.Errorf("%s: findings %q", name, a.Findings)
+		}
+	}
+
+	a, err := AnalyzeCurve(toyCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Cofactor.Cmp(big.NewInt(4)) != 0 || a.Provenance != "unknown" || len(a.Findings) == 0 {
+		t.Errorf("toy curve: cofactor %s, provenance %q, findings %q", a.Cofactor, a.Provenance, a.Findings)
+	}
+
+	if _, err := AnalyzeCurve(NamedCurveFromOid(oidSect283k1)); err == nil {
+		t.Error("binary curve analyzed")
+	}
+}
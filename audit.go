@@ -0,0 +1,109 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// SignatureRecord is a signature to audit, with the public key that
+// verifies it. Hash is the hash value that was signed; it is optional but
+// needed to recover a leaked key.
+type SignatureRecord struct {
+	Pub  *PublicKey
+	Hash []byte
+	R, S *big.Int
+}
+
+// ReuseWarning reports two signatures made by the same key with the same
+// nonce, found at indexes First and Second of the audited records.
+type ReuseWarning struct {
+	First, Second int
+	Pub           *PublicKey
+
+	// Recovered is the private key computed from the two signatures, or
+	// nil if a hash value was missing or the recovery did not give a key
+	// matching Pub.
+	Recovered *PrivateKey
+}
+
+// AuditNonceReuse looks for signatures that share r under the same public
+// key. A repeated r means a repeated nonce k, which leaks the private key:
+// with e = -h mod N, s = d(kr + e) for both signatures gives
+//
+//	d = (s1 - s2) / (e1 - e2) mod N
+//
+// When both records carry their hash value the key is recovered this way
+// and checked against the public key, to prove the leak. Identical records
+// are not reported.
+func AuditNonceReuse(signatures []SignatureRecord) []ReuseWarning {
+	var warnings []ReuseWarning
+
+	seen := make(map[string][]int)
+
+	for i, rec := range signatures {
+		if rec.Pub == nil || rec.Pub.Curve == nil || rec.Pub.X == nil || rec.Pub.Y == nil ||
+			rec.R == nil || rec.S == nil {
+			continue
+		}
+
+		id := string(elliptic.Marshal(rec.Pub.Curve, rec.Pub.X, rec.Pub.Y)) + "/" + string(rec.R.Bytes())
+
+		for _, j := range seen[id] {
+			prev := signatures[j]
+			if prev.Pub.Curve != rec.Pub.Curve ||
+				(prev.S.Cmp(rec.S) == 0 && string(prev.Hash) == string(rec.Hash)) {
+				continue
+			}
+
+			warnings = append(warnings, ReuseWarning{
+				First:     j,
+				Second:    i,
+				Pub:       rec.Pub,
+				Recovered: recoverReusedNonceKey(prev, rec),
+			})
+		}
+
+		seen[id] = append(seen[id], i)
+	}
+
+	return warnings
+}
+
+// recoverReusedNonceKey computes the private key from two signatures with
+// the same nonce, or returns nil.
+func recoverReusedNonceKey(a, b SignatureRecord) *PrivateKey {
+	if len(a.Hash) == 0 || len(b.Hash) == 0 {
+		return nil
+	}
+
+	curve := a.Pub.Curve
+	n := curve.Params().N
+
+	// e1 - e2 = -h1 + h2
+	de := new(big.Int).Sub(hashToInt(b.Hash, n), hashToInt(a.Hash, n))
+	de.Mod(de, n)
+
+	deInv := new(big.Int).ModInverse(de, n)
+	if deInv == nil {
+		return nil
+	}
+
+	d := new(big.Int).Sub(a.S, b.S)
+	d.Mul(d, deInv)
+	d.Mod(d, n)
+
+	if d.Sign() == 0 {
+		return nil
+	}
+
+	priv := new(PrivateKey)
+	priv.Curve = curve
+	priv.D = d
+	priv.X, priv.Y = XY(d, curve)
+
+	if !bigIntEqual(priv.X, a.Pub.X) || !bigIntEqual(priv.Y, a.Pub.Y) {
+		return nil
+	}
+
+	return priv
+}
@@ -0,0 +1,70 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestAuditNonceReuse(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	k := big.NewInt(0x1234567)
+
+	sign := func(msg string, fixed bool) SignatureRecord {
+		hash := sha256.Sum256([]byte(msg))
+
+		nonce := func() (*big.Int, error) { return randFieldElement(rand.Reader, priv.Curve) }
+		if fixed {
+			nonce = func() (*big.Int, error) { return k, nil }
+		}
+
+		r, s, err := signDigestWithBaseMult(priv, hash[:], nonce, priv.Curve.ScalarBaseMult)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return SignatureRecord{Pub: pub, Hash: hash[:], R: r, S: s}
+	}
+
+	first, fresh, second := sign("first", true), sign("fresh", false), sign("second", true)
+
+	warnings := AuditNonceReuse([]SignatureRecord{first, fresh, second, first})
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2", len(warnings))
+	}
+
+	for _, w := range warnings {
+		if w.Pub != pub {
+			t.Error("warning names another key")
+		}
+
+		if w.Recovered == nil || w.Recovered.D.Cmp(priv.D) != 0 {
+			t.Errorf("records %d and %d: private key not recovered", w.First, w.Second)
+		}
+	}
+
+	// The repeated record 3 pairs with record 2 only; being identical to
+	// record 0 it is not reported against it.
+	if w := warnings[0]; w.First != 0 || w.Second != 2 {
+		t.Errorf("first warning for records %d and %d, want 0 and 2", w.First, w.Second)
+	}
+
+	if w := warnings[1]; w.First != 2 || w.Second != 3 {
+		t.Errorf("second warning for records %d and %d, want 2 and 3", w.First, w.Second)
+	}
+
+	// Without the hash values the reuse is still found but the key is not
+	// recovered.
+	first.Hash, second.Hash = nil, nil
+
+	warnings = AuditNonceReuse([]SignatureRecord{first, second})
+	if len(warnings) != 1 || warnings[0].Recovered != nil {
+		t.Errorf("got %+v, want one warning without a recovered key", warnings)
+	}
+
+	if warnings := AuditNonceReuse([]SignatureRecord{fresh, sign("other", false)}); len(warnings) != 0 {
+		t.Errorf("fresh nonces reported: %+v", warnings)
+	}
+}
@@ -0,0 +1,187 @@
+package ecgdsa
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+var ErrMixedCurves = errors.New("ecgdsa: batch keys are on different curves")
+
+// batchPrecomputeMin is the smallest batch for which SignBatch builds a
+// PrecomputedSigner table. The table takes about as long as a few plain
+// signatures to build, so smaller batches sign without it.
+const batchPrecomputeMin = 16
+
+// SignBatch signs every hash value in hashes and returns the ASN.1 encoded
+// signatures in the same order. The work is spread over all CPUs; rand is
+// shared between them behind a lock, so each signature still draws its own
+// fresh nonce. Batches of at least batchPrecomputeMin hash values share
+// one PrecomputedSigner table.
+func (priv *PrivateKey) SignBatch(rand io.Reader, hashes [][]byte) ([][]byte, error) {
+	return priv.SignBatchContext(context.Background(), rand, hashes)
+}
+
+// SignBatchContext is like SignBatch but stops when ctx is done. It then
+// returns ctx.Err() together with the signatures made so far; entries that
+// were not signed are nil.
+func (priv *PrivateKey) SignBatchContext(ctx context.Context, rand io.Reader, hashes [][]byte) ([][]byte, error) {
+	if rand == nil {
+		return nil, ErrNilRand
+	}
+
+	sigs := make([][]byte, len(hashes))
+	if len(hashes) == 0 {
+		return sigs, nil
+	}
+
+	signer := &PrecomputedSigner{priv: priv}
+	if len(hashes) >= batchPrecomputeMin {
+		var err error
+		if signer, err = NewPrecomputedSigner(priv); err != nil {
+			return nil, err
+		}
+	}
+
+	rand = &lockedReader{r: rand}
+
+	workers := runtime.NumCPU()
+	if workers > len(hashes) {
+		workers = len(hashes)
+	}
+
+	jobs := make(chan int)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := range jobs {
+				if errs[w] != nil || ctx.Err() != nil {
+					continue
+				}
+
+				sigs[i], errs[w] = signer.Sign(rand, hashes[i])
+			}
+		}(w)
+	}
+
+feed:
+	for i := range hashes {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return sigs, err
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sigs, nil
+}
+
+// VerifyBatch verifies the ASN.1 signatures sigs[i] of the hash values
+// hashes[i] under pubs[i]. It returns whether every signature is valid and
+// the result of each entry. All keys must be on the same curve and the
+// three slices must have the same length; otherwise the batch is rejected
+// with false and a nil result slice. VerifyBatchContext reports why.
+//
+// The signatures are verified one by one, spread over all CPUs. A
+// randomized linear combination of the verification equations would need
+// the nonce points R_i, but an EC-GDSA signature only carries r = x(R) mod
+// N, which leaves up to four candidates for each R_i, so a single
+// multi-scalar multiplication cannot cover the batch.
+func VerifyBatch(pubs []*PublicKey, hashes [][]byte, sigs [][]byte) (bool, []bool) {
+	ok, results, err := VerifyBatchContext(context.Background(), pubs, hashes, sigs)
+	if err != nil {
+		return false, nil
+	}
+
+	return ok, results
+}
+
+// VerifyBatchContext is like VerifyBatch but stops when ctx is done and
+// returns ctx.Err(); entries that were not verified are false. It returns
+// ErrMixedCurves if the keys are on different curves.
+func VerifyBatchContext(ctx context.Context, pubs []*PublicKey, hashes [][]byte, sigs [][]byte) (bool, []bool, error) {
+	if len(hashes) != len(pubs) || len(sigs) != len(pubs) {
+		return false, nil, errors.New("ecgdsa: batch slices have different lengths")
+	}
+
+	results := make([]bool, len(pubs))
+	if len(pubs) == 0 {
+		return true, results, nil
+	}
+
+	for _, pub := range pubs {
+		if pub == nil || pub.Curve == nil {
+			return false, nil, ErrParametersNotSetUp
+		}
+
+		if !sameCurve(pub.Curve, pubs[0].Curve) {
+			return false, nil, ErrMixedCurves
+		}
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(pubs) {
+		workers = len(pubs)
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				r, s, err := parseSignature(sigs[i])
+				results[i] = err == nil && verifyDigest(pubs[i], hashes[i], r, s)
+			}
+		}()
+	}
+
+feed:
+	for i := range pubs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return false, results, err
+	}
+
+	for _, ok := range results {
+		if !ok {
+			return false, results, nil
+		}
+	}
+
+	return true, results, nil
+}
@@ -0,0 +1,225 @@
+package ecgdsa
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSignBatch(t *testing.T) {
+	// P-256 with base point 2G uses the PrecomputedSigner table.
+	custom := *elliptic.P256().Params()
+	custom.Name = "P-256/2G"
+	custom.Gx, custom.Gy = elliptic.P256().Double(custom.Gx, custom.Gy)
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), &custom} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, n := range []int{0, 1, batchPrecomputeMin - 1, batchPrecomputeMin, 3 * batchPrecomputeMin} {
+			hashes := make([][]byte, n)
+			for i := range hashes {
+				hash := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+				hashes[i] = hash[:]
+			}
+
+			sigs, err := priv.SignBatch(rand.Reader, hashes)
+			if err != nil {
+				t.Fatalf("%s, %d: %v", curve.Params().Name, n, err)
+			}
+
+			if len(sigs) != n {
+				t.Fatalf("%s, %d: got %d signatures", curve.Params().Name, n, len(sigs))
+			}
+
+			seen := make(map[string]bool)
+
+			for i, sig := range sigs {
+				if !VerifyASN1(&priv.PublicKey, hashes[i], sig) {
+					t.Errorf("%s, %d: signature %d does not verify", curve.Params().Name, n, i)
+				}
+
+				// Distinct messages and fresh nonces give distinct r.
+				r, _, err := parseSignature(sig)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if seen[r.String()] {
+					t.Errorf("%s, %d: r of signature %d repeats", curve.Params().Name, n, i)
+				}
+				seen[r.String()] = true
+			}
+		}
+	}
+}
+
+// cancelCurve cancels a context on the given ScalarBaseMult call, which
+// verifyDigest makes once per signature.
+type cancelCurve struct {
+	elliptic.Curve
+	calls  atomic.Int32
+	after  int32
+	cancel context.CancelFunc
+}
+
+func (c *cancelCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	if c.calls.Add(1) == c.after {
+		c.cancel()
+	}
+
+	return c.Curve.ScalarBaseMult(k)
+}
+
+// TestVerifyBatchContextCancel cancels a large batch after a few
+// verifications and checks that the batch stops early with ctx.Err() and
+// the results of the entries verified so far.
+func TestVerifyBatchContextCancel(t *testing.T) {
+	const n = 2000
+
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	sig, err := SignASN1(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	curve := &cancelCurve{Curve: priv.Curve, after: 10, cancel: cancel}
+	pub := &PublicKey{Curve: curve, X: priv.X, Y: priv.Y}
+
+	pubs := make([]*PublicKey, n)
+	hashes := make([][]byte, n)
+	sigs := make([][]byte, n)
+	for i := range pubs {
+		pubs[i], hashes[i], sigs[i] = pub, hash[:], sig
+	}
+
+	ok, results, err := VerifyBatchContext(ctx, pubs, hashes, sigs)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	if ok {
+		t.Error("cancelled batch reported as valid")
+	}
+
+	if len(results) != n {
+		t.Fatalf("got %d results, want %d", len(results), n)
+	}
+
+	verified := 0
+	for _, r := range results {
+		if r {
+			verified++
+		}
+	}
+
+	// Every signature is valid, so the true entries are those verified
+	// before the batch stopped.
+	if verified < int(curve.after) || verified == n {
+		t.Errorf("%d of %d entries verified", verified, n)
+	}
+
+	if calls := int(curve.calls.Load()); calls != verified {
+		t.Errorf("%d verifications but %d true results", calls, verified)
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	const n = 20
+
+	pubs := make([]*PublicKey, n)
+	hashes := make([][]byte, n)
+	sigs := make([][]byte, n)
+
+	for i := range pubs {
+		priv := testKey(t)
+		hash := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+
+		sig, err := SignASN1(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pubs[i], hashes[i], sigs[i] = &priv.PublicKey, hash[:], sig
+	}
+
+	// with returns a copy of the batch with entry i changed by change.
+	with := func(i int, change func(pubs []*PublicKey, hashes, sigs [][]byte)) ([]*PublicKey, [][]byte, [][]byte) {
+		p := append([]*PublicKey{}, pubs...)
+		h := append([][]byte{}, hashes...)
+		s := append([][]byte{}, sigs...)
+		change(p, h, s)
+		return p, h, s
+	}
+
+	for _, tt := range []struct {
+		name   string
+		bad    int
+		change func(pubs []*PublicKey, hashes, sigs [][]byte)
+	}{
+		{"valid", -1, func([]*PublicKey, [][]byte, [][]byte) {}},
+		{"other hash", 3, func(_ []*PublicKey, hashes, _ [][]byte) { hashes[3] = hashes[4] }},
+		{"other key", 0, func(pubs []*PublicKey, _, _ [][]byte) { pubs[0] = pubs[1] }},
+		{"swapped signature", 19, func(_ []*PublicKey, _, sigs [][]byte) { sigs[19] = sigs[18] }},
+		{"not DER", 7, func(_ []*PublicKey, _, sigs [][]byte) { sigs[7] = []byte("garbage") }},
+	} {
+		p, h, s := with(tt.bad, tt.change)
+
+		ok, results := VerifyBatch(p, h, s)
+		if ok != (tt.bad < 0) || len(results) != n {
+			t.Fatalf("%s: got %v with %d results", tt.name, ok, len(results))
+		}
+
+		for i, result := range results {
+			if result != (i != tt.bad) {
+				t.Errorf("%s: entry %d: got %v", tt.name, i, result)
+			}
+		}
+	}
+
+	if ok, results := VerifyBatch(nil, nil, nil); !ok || len(results) != 0 {
+		t.Errorf("empty batch: got %v, %v", ok, results)
+	}
+
+	p384 := *pubs[5]
+	p384.Curve = elliptic.P384()
+	mixed, _, _ := with(5, func(pubs []*PublicKey, _, _ [][]byte) { pubs[5] = &p384 })
+
+	if ok, results := VerifyBatch(mixed, hashes, sigs); ok || results != nil {
+		t.Errorf("mixed curves: got %v, %v", ok, results)
+	}
+
+	if _, _, err := VerifyBatchContext(context.Background(), mixed, hashes, sigs); !errors.Is(err, ErrMixedCurves) {
+		t.Errorf("mixed curves: got %v, want ErrMixedCurves", err)
+	}
+
+	nilKey, _, _ := with(2, func(pubs []*PublicKey, _, _ [][]byte) { pubs[2] = nil })
+
+	for _, tt := range []struct {
+		name   string
+		pubs   []*PublicKey
+		hashes [][]byte
+		sigs   [][]byte
+	}{
+		{"nil key", nilKey, hashes, sigs},
+		{"short hashes", pubs, hashes[1:], sigs},
+		{"short signatures", pubs, hashes, sigs[1:]},
+	} {
+		if ok, results := VerifyBatch(tt.pubs, tt.hashes, tt.sigs); ok || results != nil {
+			t.Errorf("%s: got %v, %v", tt.name, ok, results)
+		}
+	}
+}
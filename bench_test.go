@@ -0,0 +1,164 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+// BenchmarkCompare measures signing and verifying a SHA-256 digest with
+// EC-GDSA and with crypto/ecdsa on the curves both support.
+func BenchmarkCompare(b *testing.B) {
+	digest := sha256.Sum256([]byte("ecgdsa benchmark"))
+
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		epriv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		r, s, err := signDigest(rand.Reader, priv, digest[:])
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		esig, err := ecdsa.SignASN1(rand.Reader, epriv, digest[:])
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		name := curve.Params().Name
+
+		b.Run(name+"/ecgdsa/sign", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				signDigest(rand.Reader, priv, digest[:])
+			}
+		})
+
+		b.Run(name+"/ecgdsa/verify", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				verifyDigest(&priv.PublicKey, digest[:], r, s)
+			}
+		})
+
+		b.Run(name+"/ecdsa/sign", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ecdsa.SignASN1(rand.Reader, epriv, digest[:])
+			}
+		})
+
+		b.Run(name+"/ecdsa/verify", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ecdsa.VerifyASN1(&epriv.PublicKey, digest[:], esig)
+			}
+		})
+	}
+}
+
+// BenchmarkPrecomputed measures signing a SHA-256 digest with the plain
+// signing path and with a PrecomputedSigner for the same key. On
+// brainpoolP256r1 the signer uses its table; P-256 is the control, where
+// it falls back to the plain path.
+func BenchmarkPrecomputed(b *testing.B) {
+	digest := sha256.Sum256([]byte("ecgdsa benchmark"))
+
+	for _, curve := range []elliptic.Curve{brainpool.P256r1(), elliptic.P256()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		signer, err := NewPrecomputedSigner(priv)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		name := curve.Params().Name
+
+		b.Run(name+"/plain", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				signDigest(rand.Reader, priv, digest[:])
+			}
+		})
+
+		b.Run(name+"/precomputed", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				signer.Sign(rand.Reader, digest[:])
+			}
+		})
+	}
+}
+
+// BenchmarkSignBatch measures signing 64 SHA-256 digests with SignBatch
+// and with a loop over PrivateKey.Sign.
+func BenchmarkSignBatch(b *testing.B) {
+	hashes := make([][]byte, 64)
+	for i := range hashes {
+		hash := sha256.Sum256([]byte{byte(i)})
+		hashes[i] = hash[:]
+	}
+
+	for _, curve := range []elliptic.Curve{brainpool.P256r1(), elliptic.P256()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		name := curve.Params().Name
+
+		b.Run(name+"/batch", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				priv.SignBatch(rand.Reader, hashes)
+			}
+		})
+
+		b.Run(name+"/loop", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, hash := range hashes {
+					priv.Sign(rand.Reader, hash, nil)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecodePEMKeys measures decoding a bundle of 32 public keys
+// through a reader and from memory.
+func BenchmarkDecodePEMKeys(b *testing.B) {
+	data := pemPublicKeyBundle(b, 32)
+	discard := func(interface{}) error { return nil }
+
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			DecodePEMKeys(bytes.NewReader(data), discard)
+		}
+	})
+
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			DecodePEMKeysBytes(data, discard)
+		}
+	})
+}
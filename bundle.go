@@ -0,0 +1,91 @@
+package ecgdsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// verifierBundle is the structure written by MarshalVerifierBundle:
+//
+//	VerifierBundle ::= SEQUENCE {
+//	  version       INTEGER (0),
+//	  hashAlgorithm AlgorithmIdentifier,
+//	  keys          SEQUENCE OF SubjectPublicKeyInfo
+//	}
+type verifierBundle struct {
+	Version       int
+	HashAlgorithm pkix.AlgorithmIdentifier
+	Keys          []asn1.RawValue
+}
+
+// MarshalVerifierBundle packs pubs, which may be on different curves,
+// together with the hash algorithm their signatures are expected to use,
+// so that a verifier can be provisioned from one artifact. hashOID must be
+// a hash registered with RegisterHash.
+func MarshalVerifierBundle(pubs []*PublicKey, hashOID asn1.ObjectIdentifier) ([]byte, error) {
+	if len(pubs) == 0 {
+		return nil, errors.New("ecgdsa: no keys for verifier bundle")
+	}
+
+	if _, ok := hashFromOid(hashOID); !ok {
+		return nil, fmt.Errorf("ecgdsa: unknown hash algorithm %s", hashOID)
+	}
+
+	bundle := verifierBundle{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: hashOID},
+		Keys:          make([]asn1.RawValue, len(pubs)),
+	}
+
+	for i, pub := range pubs {
+		der, err := MarshalPublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("ecgdsa: verifier bundle key %d: %w", i, err)
+		}
+
+		bundle.Keys[i] = asn1.RawValue{FullBytes: der}
+	}
+
+	return asn1.Marshal(bundle)
+}
+
+// ParseVerifierBundle parses a bundle written by MarshalVerifierBundle and
+// returns its keys and hash algorithm.
+func ParseVerifierBundle(der []byte) (pubs []*PublicKey, hashOID asn1.ObjectIdentifier, err error) {
+	if len(der) == 0 {
+		return nil, nil, ErrEmptyInput
+	}
+
+	var bundle verifierBundle
+
+	rest, err := asn1.Unmarshal(der, &bundle)
+	if err != nil {
+		return nil, nil, err
+	} else if len(rest) != 0 {
+		return nil, nil, errors.New("ecgdsa: trailing data after verifier bundle")
+	}
+
+	if bundle.Version != 0 {
+		return nil, nil, fmt.Errorf("ecgdsa: unknown verifier bundle version %d", bundle.Version)
+	}
+
+	hashOID = bundle.HashAlgorithm.Algorithm
+	if _, ok := hashFromOid(hashOID); !ok {
+		return nil, nil, fmt.Errorf("ecgdsa: unknown hash algorithm %s", hashOID)
+	}
+
+	if len(bundle.Keys) == 0 {
+		return nil, nil, errors.New("ecgdsa: verifier bundle has no keys")
+	}
+
+	pubs = make([]*PublicKey, len(bundle.Keys))
+	for i, key := range bundle.Keys {
+		pubs[i], err = ParsePublicKey(key.FullBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ecgdsa: verifier bundle key %d: %w", i, err)
+		}
+	}
+
+	return pubs, hashOID, nil
+}
@@ -0,0 +1,112 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/secp256k1"
+)
+
+func TestVerifierBundle(t *testing.T) {
+	sha384OID := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+
+	var privs []*PrivateKey
+	var pubs []*PublicKey
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), brainpool.P256r1(), secp256k1.S256()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		privs = append(privs, priv)
+		pubs = append(pubs, &priv.PublicKey)
+	}
+
+	der, err := MarshalVerifierBundle(pubs, sha384OID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, hashOID, err := ParseVerifierBundle(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !hashOID.Equal(sha384OID) {
+		t.Errorf("hash %s, want %s", hashOID, sha384OID)
+	}
+
+	if len(got) != len(pubs) {
+		t.Fatalf("got %d keys, want %d", len(got), len(pubs))
+	}
+
+	msg := []byte("message")
+
+	for i, pub := range got {
+		if !pub.Equal(pubs[i]) || pub.Curve != pubs[i].Curve {
+			t.Errorf("key %d differs", i)
+		}
+
+		// The keys verify signatures made with the bundled hash.
+		sig, err := Sign(rand.Reader, privs[i], sha512.New384, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok, err := VerifyWithHashOID(pub, hashOID, msg, sig); !ok || err != nil {
+			t.Errorf("key %d: got %v, %v", i, ok, err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		pubs    []*PublicKey
+		hashOID asn1.ObjectIdentifier
+	}{
+		{"no keys", nil, sha384OID},
+		{"unknown hash", pubs, asn1.ObjectIdentifier{1, 2, 3}},
+		{"empty key", []*PublicKey{pubs[0], {}}, sha384OID},
+	} {
+		if _, err := MarshalVerifierBundle(tt.pubs, tt.hashOID); err == nil {
+			t.Errorf("%s: marshalled", tt.name)
+		}
+	}
+
+	marshal := func(b verifierBundle) []byte {
+		der, err := asn1.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	keyDER, err := MarshalPublicKey(pubs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []asn1.RawValue{{FullBytes: keyDER}}
+	hash := pkix.AlgorithmIdentifier{Algorithm: sha384OID}
+
+	for _, tt := range []struct {
+		name string
+		der  []byte
+	}{
+		{"empty", nil},
+		{"trailing data", append(append([]byte{}, der...), 0)},
+		{"version 1", marshal(verifierBundle{Version: 1, HashAlgorithm: hash, Keys: keys})},
+		{"unknown hash", marshal(verifierBundle{HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 3}}, Keys: keys})},
+		{"no keys", marshal(verifierBundle{HashAlgorithm: hash, Keys: []asn1.RawValue{}})},
+		{"invalid key", marshal(verifierBundle{HashAlgorithm: hash, Keys: []asn1.RawValue{{FullBytes: []byte{0x30, 0x00}}}})},
+	} {
+		if _, _, err := ParseVerifierBundle(tt.der); err == nil {
+			t.Errorf("%s: parsed", tt.name)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// SignatureCodec converts the (r, s) pair of a signature to and from a
+// wire format.
+type SignatureCodec interface {
+	Encode(r, s *big.Int, curve elliptic.Curve) ([]byte, error)
+	Decode(data []byte, curve elliptic.Curve) (r, s *big.Int, err error)
+}
+
+var (
+	// DERCodec encodes signatures as an ASN.1 SEQUENCE of two INTEGERs.
+	DERCodec SignatureCodec = derCodec{}
+
+	// RawCodec encodes signatures as r || s, each left-padded to the byte
+	// length of the curve order (IEEE P1363).
+	RawCodec SignatureCodec = rawCodec{}
+
+	// CompactCodec encodes signatures as len(r) || r || len(s) || s with
+	// one length byte and minimal big-endian integers.
+	CompactCodec SignatureCodec = compactCodec{}
+)
+
+var signatureCodecs = map[string]SignatureCodec{
+	"der":     DERCodec,
+	"raw":     RawCodec,
+	"compact": CompactCodec,
+}
+
+// RegisterSignatureCodec makes codec available by name. Registering an
+// existing name replaces the previous codec.
+func RegisterSignatureCodec(name string, codec SignatureCodec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	signatureCodecs[name] = codec
+}
+
+// SignatureCodecByName returns the codec registered under name.
+func SignatureCodecByName(name string) (SignatureCodec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	codec, ok := signatureCodecs[name]
+	return codec, ok
+}
+
+// SupportedSignatureFormats returns the sorted names of the registered
+// signature codecs, including those added with RegisterSignatureCodec.
+func SupportedSignatureFormats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(signatureCodecs))
+	for name := range signatureCodecs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// SignWithCodec signs data and encodes the signature with codec.
+func SignWithCodec(rand io.Reader, priv *PrivateKey, h Hasher, data []byte, codec SignatureCodec) ([]byte, error) {
+	r, s, err := SignToRS(rand, priv, h, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Encode(r, s, priv.Curve)
+}
+
+// VerifyWithCodec decodes sig with codec and verifies it.
+func VerifyWithCodec(pub *PublicKey, h Hasher, data, sig []byte, codec SignatureCodec) bool {
+	if pub == nil || pub.Curve == nil {
+		return false
+	}
+
+	r, s, err := codec.Decode(sig, pub.Curve)
+	if err != nil {
+		return false
+	}
+
+	return VerifyWithRS(pub, h, data, r, s)
+}
+
+type derCodec struct{}
+
+func (derCodec) Encode(r, s *big.Int, curve elliptic.Curve) ([]byte, error) {
+	if !checkSignatureRange(curve, r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	return encodeSignature(r, s)
+}
+
+func (derCodec) Decode(data []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	r, s, err = parseSignature(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !checkSignatureRange(curve, r, s) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	return r, s, nil
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Encode(r, s *big.Int, curve elliptic.Curve) ([]byte, error) {
+	if !checkSignatureRange(curve, r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	size := rawSignatureSize(curve)
+
+	raw := make([]byte, 2*size)
+	r.FillBytes(raw[:size])
+	s.FillBytes(raw[size:])
+
+	return raw, nil
+}
+
+func (rawCodec) Decode(data []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	size := rawSignatureSize(curve)
+	if len(data) != 2*size {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	r = new(big.Int).SetBytes(data[:size])
+	s = new(big.Int).SetBytes(data[size:])
+
+	if !checkSignatureRange(curve, r, s) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	return r, s, nil
+}
+
+type compactCodec struct{}
+
+func (compactCodec) Encode(r, s *big.Int, curve elliptic.Curve) ([]byte, error) {
+	if !checkSignatureRange(curve, r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	rb, sb := r.Bytes(), s.Bytes()
+	if len(rb) > 255 || len(sb) > 255 {
+		return nil, ErrInvalidSignature
+	}
+
+	out := make([]byte, 0, 2+len(rb)+len(sb))
+	out = append(out, byte(len(rb)))
+	out = append(out, rb...)
+	out = append(out, byte(len(sb)))
+	out = append(out, sb...)
+
+	return out, nil
+}
+
+func (compactCodec) Decode(data []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	rb, rest, ok := readCompactInt(data)
+	if !ok {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	sb, rest, ok := readCompactInt(rest)
+	if !ok || len(rest) != 0 {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	r = new(big.Int).SetBytes(rb)
+	s = new(big.Int).SetBytes(sb)
+
+	if !checkSignatureRange(curve, r, s) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	return r, s, nil
+}
+
+// readCompactInt reads a length-prefixed integer and rejects leading
+// zero bytes so that every signature has a single encoding.
+func readCompactInt(data []byte) (b, rest []byte, ok bool) {
+	if len(data) < 1 {
+		return nil, nil, false
+	}
+
+	n := int(data[0])
+	if n == 0 || len(data) < 1+n || data[1] == 0 {
+		return nil, nil, false
+	}
+
+	return data[1 : 1+n], data[1+n:], true
+}
@@ -0,0 +1,169 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+// hexCodec is a custom codec: the raw signature in hex.
+type hexCodec struct{}
+
+func (hexCodec) Encode(r, s *big.Int, curve elliptic.Curve) ([]byte, error) {
+	raw, err := RawCodec.Encode(r, s, curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(raw)), nil
+}
+
+func (hexCodec) Decode(data []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	raw, err := hex.DecodeString(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RawCodec.Decode(raw, curve)
+}
+
+func TestSignatureCodecs(t *testing.T) {
+	RegisterSignatureCodec("test-hex", hexCodec{})
+
+	formats := SupportedSignatureFormats()
+	if !sort.StringsAreSorted(formats) {
+		t.Errorf("formats %q not sorted", formats)
+	}
+
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	msg := []byte("message")
+	size := rawSignatureSize(priv.Curve)
+
+	for _, tt := range []struct {
+		name   string
+		codec  SignatureCodec
+		length func(r, s *big.Int) int
+	}{
+		{"der", DERCodec, nil},
+		{"raw", RawCodec, func(r, s *big.Int) int { return 2 * size }},
+		{"compact", CompactCodec, func(r, s *big.Int) int { return 2 + len(r.Bytes()) + len(s.Bytes()) }},
+		{"test-hex", hexCodec{}, func(r, s *big.Int) int { return 4 * size }},
+	} {
+		codec, ok := SignatureCodecByName(tt.name)
+		if !ok || codec != tt.codec {
+			t.Fatalf("%s: not registered", tt.name)
+		}
+
+		i := sort.SearchStrings(formats, tt.name)
+		if i == len(formats) || formats[i] != tt.name {
+			t.Errorf("%s: missing from %q", tt.name, formats)
+		}
+
+		sig, err := SignWithCodec(rand.Reader, priv, sha256.New, msg, codec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if !VerifyWithCodec(pub, sha256.New, msg, sig, codec) {
+			t.Errorf("%s: signature rejected", tt.name)
+		}
+
+		if VerifyWithCodec(pub, sha256.New, []byte("other"), sig, codec) {
+			t.Errorf("%s: other message accepted", tt.name)
+		}
+
+		r, s, err := codec.Decode(sig, priv.Curve)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if !VerifyWithRS(pub, sha256.New, msg, r, s) {
+			t.Errorf("%s: decoded (r, s) rejected", tt.name)
+		}
+
+		if tt.length != nil && len(sig) != tt.length(r, s) {
+			t.Errorf("%s: signature is %d bytes, want %d", tt.name, len(sig), tt.length(r, s))
+		}
+
+		again, err := codec.Encode(r, s, priv.Curve)
+		if err != nil || string(again) != string(sig) {
+			t.Errorf("%s: re-encoding changed the signature", tt.name)
+		}
+
+		if _, err := codec.Encode(new(big.Int), s, priv.Curve); err == nil {
+			t.Errorf("%s: r = 0 encoded", tt.name)
+		}
+
+		if _, _, err := codec.Decode(sig[:len(sig)-1], priv.Curve); err == nil {
+			t.Errorf("%s: truncated signature decoded", tt.name)
+		}
+
+		if VerifyWithCodec(pub, sha256.New, msg, append(append([]byte{}, sig...), 0), codec) {
+			t.Errorf("%s: trailing byte accepted", tt.name)
+		}
+	}
+
+	if _, ok := SignatureCodecByName("unknown"); ok {
+		t.Error("unknown codec found")
+	}
+
+	// Compact integers have a single encoding.
+	if _, _, err := CompactCodec.Decode([]byte{2, 0, 1, 1, 1}, priv.Curve); err == nil {
+		t.Error("compact integer with a leading zero decoded")
+	}
+}
+
+func TestSupportedSignatureFormats(t *testing.T) {
+	const name = "test-formats"
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(signatureCodecs, name)
+		registryMu.Unlock()
+	})
+
+	contains := func(formats []string, name string) bool {
+		i := sort.SearchStrings(formats, name)
+		return i < len(formats) && formats[i] == name
+	}
+
+	before := SupportedSignatureFormats()
+	for _, builtin := range []string{"compact", "der", "raw"} {
+		if !contains(before, builtin) {
+			t.Errorf("%s missing from %q", builtin, before)
+		}
+	}
+
+	if contains(before, name) {
+		t.Fatalf("%s listed before it was registered", name)
+	}
+
+	RegisterSignatureCodec(name, hexCodec{})
+
+	after := SupportedSignatureFormats()
+	if !sort.StringsAreSorted(after) {
+		t.Errorf("formats %q not sorted", after)
+	}
+
+	if !contains(after, name) || len(after) != len(before)+1 {
+		t.Errorf("got %q after registering %s to %q", after, name, before)
+	}
+
+	// Replacing a codec does not list its name twice.
+	RegisterSignatureCodec(name, RawCodec)
+
+	if again := SupportedSignatureFormats(); len(again) != len(after) {
+		t.Errorf("got %q after replacing %s", again, name)
+	}
+
+	// The result is a copy.
+	after[0] = "changed"
+	if contains(SupportedSignatureFormats(), "changed") {
+		t.Error("result shares the registry")
+	}
+}
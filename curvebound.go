@@ -0,0 +1,55 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"io"
+	"math/big"
+)
+
+// curveBoundData returns the data actually signed by SignCurveBound:
+//
+//	H(P || A || B || Gx || Gy || N) || message
+//
+// where P, A, B, Gx and Gy are padded to the byte length of the field, N
+// to its own byte length, and H is the signing hash. Any change of curve
+// changes the prefix and so the signed hash value.
+func curveBoundData(curve elliptic.Curve, h Hasher, message []byte) []byte {
+	params := curve.Params()
+
+	fieldLen := (params.BitSize + 7) / 8
+	if pLen := BitsToBytes(params.P.BitLen()); pLen > fieldLen {
+		fieldLen = pLen
+	}
+
+	d := h()
+	for _, v := range []*big.Int{params.P, curveA(params), params.B, params.Gx, params.Gy} {
+		d.Write(v.FillBytes(make([]byte, fieldLen)))
+	}
+
+	d.Write(params.N.FillBytes(make([]byte, BitsToBytes(params.N.BitLen()))))
+
+	return append(d.Sum(nil), message...)
+}
+
+// SignCurveBound signs message bound to the domain parameters of the
+// curve of priv: the signed data is the hash of the parameters followed
+// by message, as described at curveBoundData. A signature made this way
+// does not verify under any other curve, even one with the same order
+// size, which rules out cross-curve confusion in deployments that use
+// several curves. It does not verify with plain Verify either.
+func SignCurveBound(rand io.Reader, priv *PrivateKey, h Hasher, message []byte) ([]byte, error) {
+	if priv == nil || priv.Curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	return Sign(rand, priv, h, curveBoundData(priv.Curve, h, message))
+}
+
+// VerifyCurveBound verifies a signature made by SignCurveBound.
+func VerifyCurveBound(pub *PublicKey, h Hasher, message, sig []byte) bool {
+	if pub == nil || pub.Curve == nil {
+		return false
+	}
+
+	return Verify(pub, h, curveBoundData(pub.Curve, h, message), sig)
+}
@@ -0,0 +1,60 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestSignCurveBound(t *testing.T) {
+	message := []byte("message")
+
+	p256 := testKey(t)
+
+	bp256, err := GenerateKey(rand.Reader, brainpool.P256r1())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(curveBoundData(elliptic.P256(), sha256.New, message), curveBoundData(brainpool.P256r1(), sha256.New, message)) {
+		t.Fatal("P-256 and brainpoolP256r1 give the same signed data")
+	}
+
+	for _, tt := range []struct {
+		name        string
+		priv, other *PrivateKey
+	}{
+		{"P-256", p256, bp256},
+		{"brainpoolP256r1", bp256, p256},
+	} {
+		sig, err := SignCurveBound(rand.Reader, tt.priv, sha256.New, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !VerifyCurveBound(&tt.priv.PublicKey, sha256.New, message, sig) {
+			t.Errorf("%s: signature does not verify", tt.name)
+		}
+
+		if VerifyCurveBound(&tt.priv.PublicKey, sha256.New, []byte("other"), sig) {
+			t.Errorf("%s: signature verifies for another message", tt.name)
+		}
+
+		if VerifyCurveBound(&tt.other.PublicKey, sha256.New, message, sig) {
+			t.Errorf("%s: signature verifies under the other curve", tt.name)
+		}
+
+		if Verify(&tt.priv.PublicKey, sha256.New, message, sig) {
+			t.Errorf("%s: curve-bound signature accepted by Verify", tt.name)
+		}
+
+		// The signed data is the parameter hash followed by the message.
+		if !Verify(&tt.priv.PublicKey, sha256.New, curveBoundData(tt.priv.Curve, sha256.New, message), sig) {
+			t.Errorf("%s: signature is not over curveBoundData", tt.name)
+		}
+	}
+}
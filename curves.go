@@ -3,6 +3,10 @@ package ecgdsa
 import (
 	"crypto/elliptic"
 	"encoding/asn1"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
 type namedCurveInfo struct {
@@ -12,16 +16,100 @@ type namedCurveInfo struct {
 
 var namedCurves = make([]namedCurveInfo, 0)
 
+// registryMu guards namedCurves, signatureHashes, signatureCodecs and
+// registryErrors.
+var registryMu sync.RWMutex
+
+// registryErrors records rejected registrations for VerifyRegistry.
+var registryErrors []error
+
+// registryFrozen is set by FreezeRegistry.
+var registryFrozen atomic.Bool
+
+var ErrRegistryFrozen = errors.New("ecgdsa: curve registry is frozen")
+
+// AddNamedCurve registers curve under oid. A curve or OID that is already
+// registered, or a registration after FreezeRegistry, is not added; the
+// error is recorded and reported by VerifyRegistry. Use RegisterCurve to
+// get the error directly.
 func AddNamedCurve(curve elliptic.Curve, oid asn1.ObjectIdentifier) {
+	if err := RegisterCurve(curve, oid); err != nil {
+		registryMu.Lock()
+		registryErrors = append(registryErrors, err)
+		registryMu.Unlock()
+	}
+}
+
+// RegisterCurve registers curve under oid. It fails if the curve or the
+// OID is already registered, or with ErrRegistryFrozen after
+// FreezeRegistry.
+func RegisterCurve(curve elliptic.Curve, oid asn1.ObjectIdentifier) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registryFrozen.Load() {
+		return ErrRegistryFrozen
+	}
+
+	for i := range namedCurves {
+		cur := &namedCurves[i]
+
+		if cur.oid.Equal(oid) {
+			return fmt.Errorf("ecgdsa: OID %s is already registered", oid)
+		}
+
+		if cur.namedCurve == curve {
+			return fmt.Errorf("ecgdsa: curve %s is already registered as %s", curve.Params().Name, cur.oid)
+		}
+	}
+
 	namedCurves = append(namedCurves, namedCurveInfo{
 		namedCurve: curve,
 		oid:        oid,
 	})
+
+	return nil
+}
+
+// FreezeRegistry makes the curve registry immutable. The intended
+// lifecycle is to register any extra curves at startup, call
+// FreezeRegistry once configuration is done, and then serve: from then on
+// RegisterCurve fails with ErrRegistryFrozen, AddNamedCurve records that
+// error, and lookups read the registry without any synchronization since
+// it can no longer change. Freezing cannot be undone.
+func FreezeRegistry() {
+	registryMu.Lock()
+	registryFrozen.Store(true)
+	registryMu.Unlock()
+}
+
+// registeredCurves returns the registered curves. Registration only
+// appends, so the returned slice stays valid after the lock is released;
+// once the registry is frozen no lock is needed at all.
+func registeredCurves() []namedCurveInfo {
+	if registryFrozen.Load() {
+		return namedCurves
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return namedCurves
+}
+
+// VerifyRegistry returns the conflicts recorded by AddNamedCurve and
+// RegisterHash, or nil if every registration succeeded.
+func VerifyRegistry() error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return errors.Join(registryErrors...)
 }
 
 func NamedCurveFromOid(oid asn1.ObjectIdentifier) elliptic.Curve {
-	for i := range namedCurves {
-		cur := &namedCurves[i]
+	curves := registeredCurves()
+	for i := range curves {
+		cur := &curves[i]
 		if cur.oid.Equal(oid) {
 			return cur.namedCurve
 		}
@@ -31,8 +119,9 @@ func NamedCurveFromOid(oid asn1.ObjectIdentifier) elliptic.Curve {
 }
 
 func OidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
-	for i := range namedCurves {
-		cur := &namedCurves[i]
+	curves := registeredCurves()
+	for i := range curves {
+		cur := &curves[i]
 		if cur.namedCurve == curve {
 			return cur.oid, true
 		}
@@ -41,3 +130,23 @@ func OidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
 	return asn1.ObjectIdentifier{}, false
 }
 
+// securityLevels are the conventional symmetric-equivalent strengths.
+var securityLevels = []int{256, 192, 160, 128, 112, 96, 80}
+
+// SecurityLevel returns the approximate symmetric-equivalent security of
+// curve in bits. Generic attacks on the discrete logarithm take about
+// sqrt(N) steps, so this is half the bit length of the order, rounded down
+// to the nearest conventional level (80, 96, 112, 128, 160, 192 or 256).
+// For example P-256 gives 128 and both P-521 and brainpoolP512r1 give 256.
+// Curve specific weaknesses are not taken into account.
+func SecurityLevel(curve elliptic.Curve) int {
+	bits := curve.Params().N.BitLen() / 2
+
+	for _, level := range securityLevels {
+		if bits >= level {
+			return level
+		}
+	}
+
+	return bits
+}
@@ -0,0 +1,64 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/secp256k1"
+)
+
+func TestSecurityLevel(t *testing.T) {
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		want  int
+	}{
+		{elliptic.P224(), 112},
+		{elliptic.P256(), 128},
+		{elliptic.P384(), 192},
+		{elliptic.P521(), 256},
+		{brainpool.P256r1(), 128},
+		{secp256k1.S256(), 128},
+		// Below 80 bits the level is half the order size, unrounded.
+		{toyCurve, 6},
+	} {
+		if got := SecurityLevel(tt.curve); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.curve.Params().Name, got, tt.want)
+		}
+	}
+
+	// The expected level for each order size of the registered curves:
+	// the brainpool, NIST prime and binary, NUMS and other curves.
+	levels := map[int]int{
+		160: 80,
+		192: 96,
+		224: 112,
+		254: 112, // numsp256t1, cofactor 4
+		256: 128,
+		281: 128, // K-283
+		282: 128, // B-283
+		320: 160,
+		382: 160, // numsp384t1, cofactor 4
+		384: 192,
+		407: 192, // K-409
+		409: 192, // B-409
+		510: 192, // numsp512t1, cofactor 4
+		512: 256,
+		521: 256,
+		570: 256, // K-571 and B-571
+	}
+
+	for _, c := range registeredCurves() {
+		bits := c.namedCurve.Params().N.BitLen()
+
+		want, ok := levels[bits]
+		if !ok {
+			t.Errorf("%s: no expected level for a %d-bit order", c.oid, bits)
+			continue
+		}
+
+		if got := SecurityLevel(c.namedCurve); got != want {
+			t.Errorf("%s: got %d, want %d", c.oid, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveSymmetricKey derives a length byte key from the private scalar
+// with HKDF-SHA256, using info for domain separation. The same key and
+// info always give the same output, and different info give independent
+// outputs.
+//
+// The derived key is only as secret as the signing key: anyone holding
+// the private key can recompute it, and its compromise exposes every key
+// derived from it. info must not be empty.
+func (priv *PrivateKey) DeriveSymmetricKey(info []byte, length int) ([]byte, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if len(info) == 0 {
+		return nil, errors.New("ecgdsa: empty info for key derivation")
+	}
+
+	if length <= 0 || length > 255*sha256.Size {
+		return nil, errors.New("ecgdsa: invalid derived key length")
+	}
+
+	secret := priv.D.FillBytes(make([]byte, BitsToBytes(priv.Curve.Params().N.BitLen())))
+
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, []byte("ecgdsa symmetric key"), info), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// deriveScalar derives a scalar in [1, N-1] from secret with
+// HKDF-SHA256(secret, salt, info): it reads the byte length of N plus 8
+// bytes, interprets them as a big-endian integer c and returns
+// c mod (N-1) + 1. The 64 extra bits make the bias of the reduction
+// negligible.
+func deriveScalar(curve elliptic.Curve, secret, salt, info []byte) (*big.Int, error) {
+	n := curve.Params().N
+
+	buf := make([]byte, BitsToBytes(n.BitLen())+8)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), buf); err != nil {
+		return nil, err
+	}
+
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(n, one)
+
+	d := new(big.Int).SetBytes(buf)
+	d.Mod(d, nMinus1)
+	d.Add(d, one)
+
+	return d, nil
+}
+
+// minSeedSize is the shortest seed GenerateKeyFromSeed accepts.
+const minSeedSize = 16
+
+// GenerateKeyFromSeed deterministically derives a private key on curve
+// from seed: the same seed and curve always give the same key. seed must
+// be at least 16 bytes of secret, uniformly random data; the key is only
+// as strong as the seed.
+//
+// The scalar is derived as follows, so that other implementations can
+// reproduce it:
+//
+//	okm = HKDF-SHA256(IKM = seed, salt = empty,
+//	                  info = "ecgdsa key from seed",
+//	                  L = byteLen(N) + 8)
+//	d   = (okm as big-endian integer) mod (N - 1) + 1
+//
+// and the public key is d⁻¹·G as usual. The reduction of a value 64 bits
+// longer than N replaces rejection sampling; its bias is negligible. For
+// example, on P-256 a seed of 32 zero bytes gives
+//
+//	d = b923aeee9c74588b3f4c0408a756966edbaaba6707f41a7ce9af548d494053f9
+func GenerateKeyFromSeed(curve elliptic.Curve, seed []byte) (*PrivateKey, error) {
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if len(seed) < minSeedSize {
+		return nil, errors.New("ecgdsa: seed is too short")
+	}
+
+	d, err := deriveScalar(curve, seed, nil, []byte("ecgdsa key from seed"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivateKeyFromScalar(curve, d)
+}
+
+// TestKey returns the standard test key number index on curve. It is
+// derived from public constants, so it is NOT SECRET and must never be
+// used outside tests.
+//
+// The scalar is derived as follows, so that other implementations can
+// reproduce it: with the curve OID in dotted decimal form (for example
+// "1.2.840.10045.3.1.7" for P-256),
+//
+//	okm = HKDF-SHA256(IKM = "ecgdsa test key", salt = empty,
+//	                  info = OID || 0x00 || uint32_be(index),
+//	                  L = byteLen(N) + 8)
+//	d   = (okm as big-endian integer) mod (N - 1) + 1
+//
+// and the public key is d⁻¹·G as usual. TestKey panics if curve is not
+// registered or index is negative.
+func TestKey(curve elliptic.Curve, index int) *PrivateKey {
+	oid, ok := OidFromNamedCurve(curve)
+	if !ok {
+		panic("ecgdsa: TestKey: curve is not registered")
+	}
+
+	if index < 0 || uint64(index) > math.MaxUint32 {
+		panic("ecgdsa: TestKey: index out of range")
+	}
+
+	info := append([]byte(oid.String()), 0)
+	info = binary.BigEndian.AppendUint32(info, uint32(index))
+
+	d, err := deriveScalar(curve, []byte("ecgdsa test key"), nil, info)
+	if err != nil {
+		panic(err)
+	}
+
+	priv := new(PrivateKey)
+	priv.Curve = curve
+	priv.D = d
+	priv.X, priv.Y = XY(d, curve)
+
+	return priv
+}
@@ -0,0 +1,162 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"fmt"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestGenerateKeyFromSeed(t *testing.T) {
+	priv, err := GenerateKeyFromSeed(elliptic.P256(), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "b923aeee9c74588b3f4c0408a756966edbaaba6707f41a7ce9af548d494053f9"
+	if got := fmt.Sprintf("%064x", priv.D); got != want {
+		t.Errorf("d = %s, want %s", got, want)
+	}
+
+	again, err := GenerateKeyFromSeed(elliptic.P256(), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !again.Equal(priv) {
+		t.Error("same seed gave a different key")
+	}
+}
+
+func TestDeriveSymmetricKey(t *testing.T) {
+	priv, err := GenerateKeyFromSeed(elliptic.P256(), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HKDF-SHA256(IKM = d, salt = "ecgdsa symmetric key", info = "aes key"),
+	// computed independently.
+	const want = "608341e3f94089273d2ca1080d7d93bb97a4598a26ca542df52a59a9cf7fe5e6"
+
+	key, err := priv.DeriveSymmetricKey([]byte("aes key"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprintf("%x", key); got != want {
+		t.Errorf("key = %s, want %s", got, want)
+	}
+
+	other := testKey(t)
+	seen := make(map[string]string)
+
+	for _, tt := range []struct {
+		name   string
+		priv   *PrivateKey
+		info   string
+		length int
+	}{
+		{"aes key", priv, "aes key", 32},
+		{"mac key", priv, "mac key", 32},
+		{"info prefix", priv, "aes ke", 32},
+		{"other key", other, "aes key", 32},
+		{"long", priv, "long", 255 * 32},
+	} {
+		key, err := tt.priv.DeriveSymmetricKey([]byte(tt.info), tt.length)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if len(key) != tt.length {
+			t.Errorf("%s: got %d bytes, want %d", tt.name, len(key), tt.length)
+		}
+
+		again, err := tt.priv.DeriveSymmetricKey([]byte(tt.info), tt.length)
+		if err != nil || !bytes.Equal(again, key) {
+			t.Errorf("%s: second derivation differs", tt.name)
+		}
+
+		if prev, ok := seen[string(key[:32])]; ok {
+			t.Errorf("%s: same key as %s", tt.name, prev)
+		}
+		seen[string(key[:32])] = tt.name
+	}
+
+	// A shorter output is a prefix of a longer one with the same info.
+	short, err := priv.DeriveSymmetricKey([]byte("aes key"), 16)
+	if err != nil || !bytes.Equal(short, key[:16]) {
+		t.Error("16 byte key is not a prefix of the 32 byte key")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		priv   *PrivateKey
+		info   []byte
+		length int
+	}{
+		{"empty info", priv, nil, 32},
+		{"zero length", priv, []byte("aes key"), 0},
+		{"too long", priv, []byte("aes key"), 255*32 + 1},
+		{"no key", &PrivateKey{}, []byte("aes key"), 32},
+	} {
+		if _, err := tt.priv.DeriveSymmetricKey(tt.info, tt.length); err == nil {
+			t.Errorf("%s: key derived", tt.name)
+		}
+	}
+}
+
+func TestTestKey(t *testing.T) {
+	// The scalars of the standard test keys 0 and 3 on P-256, computed
+	// independently from the documented derivation.
+	for index, want := range map[int]string{
+		0: "54b672031433e2428f0399b38b391f8d6255332f7f1ec0aa5a1af677b34916fd",
+		3: "7867752905d95bc40bac236260e736ba2ce430fa0ec261a1b2da6867118ec38e",
+	} {
+		if got := fmt.Sprintf("%064x", TestKey(elliptic.P256(), index).D); got != want {
+			t.Errorf("key %d: d = %s, want %s", index, got, want)
+		}
+	}
+
+	seen := make(map[string]string)
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), brainpool.P256r1()} {
+		for index := 0; index < 8; index++ {
+			name := fmt.Sprintf("%s #%d", curve.Params().Name, index)
+			priv := TestKey(curve, index)
+
+			if priv.Curve != curve {
+				t.Errorf("%s: key on another curve", name)
+			}
+
+			if err := priv.Validate(); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+
+			if !TestKey(curve, index).Equal(priv) {
+				t.Errorf("%s: second call gave another key", name)
+			}
+
+			if prev, ok := seen[priv.D.String()]; ok {
+				t.Errorf("%s: same scalar as %s", name, prev)
+			}
+			seen[priv.D.String()] = name
+		}
+	}
+
+	for name, fn := range map[string]func(){
+		"negative index":     func() { TestKey(elliptic.P256(), -1) },
+		"unregistered curve": func() { TestKey(toyCurve, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+
+			fn()
+		}()
+	}
+}
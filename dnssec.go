@@ -0,0 +1,95 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"io"
+)
+
+// The DNSSEC signature layout follows RFC 6605, section 4, for ECDSA: the
+// signature field of the RRSIG record is
+//
+//	r || s
+//
+// with r and s as unsigned big-endian integers, each exactly 32 bytes on
+// P-256 or 48 bytes on P-384, so the field is 64 or 96 bytes long with no
+// length or type octets. The signed data is hashed with SHA-256 on P-256
+// and SHA-384 on P-384. The matching DNSKEY public key field is X || Y, as
+// produced by MarshalPublicKeyXY. Only these two curves are allowed.
+
+// dnssecHash returns the hash RFC 6605 pairs with curve.
+func dnssecHash(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	}
+
+	return 0, errors.New("ecgdsa: DNSSEC signatures are only defined for P-256 and P-384")
+}
+
+// DERToDNSSEC converts an ASN.1 signature to the DNSSEC signature field.
+func DERToDNSSEC(curve elliptic.Curve, der []byte) ([]byte, error) {
+	if _, err := dnssecHash(curve); err != nil {
+		return nil, err
+	}
+
+	return DERToRaw(curve, der)
+}
+
+// DNSSECToDER converts a DNSSEC signature field to an ASN.1 signature. The
+// field must have exactly the length required by curve.
+func DNSSECToDER(curve elliptic.Curve, sig []byte) ([]byte, error) {
+	if _, err := dnssecHash(curve); err != nil {
+		return nil, err
+	}
+
+	return RawToDER(curve, sig)
+}
+
+// SignDNSSEC hashes data, the RRSIG RDATA and RRset in canonical form,
+// with the hash of the curve and returns the DNSSEC signature field.
+func SignDNSSEC(rand io.Reader, priv *PrivateKey, data []byte) ([]byte, error) {
+	if priv == nil || priv.Curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	h, err := dnssecHash(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	d := h.New()
+	d.Write(data)
+
+	r, s, err := signDigest(rand, priv, d.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return RawCodec.Encode(r, s, priv.Curve)
+}
+
+// VerifyDNSSEC verifies a DNSSEC signature field made by SignDNSSEC.
+func VerifyDNSSEC(pub *PublicKey, data, sig []byte) bool {
+	if pub == nil || pub.Curve == nil {
+		return false
+	}
+
+	h, err := dnssecHash(pub.Curve)
+	if err != nil {
+		return false
+	}
+
+	r, s, err := RawCodec.Decode(sig, pub.Curve)
+	if err != nil {
+		return false
+	}
+
+	d := h.New()
+	d.Write(data)
+
+	return verifyDigest(pub, d.Sum(nil), r, s)
+}
@@ -0,0 +1,122 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestDNSSEC(t *testing.T) {
+	data := []byte("RRSIG RDATA and RRset in canonical form")
+
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		hash  crypto.Hash
+		size  int
+	}{
+		{elliptic.P256(), crypto.SHA256, 32},
+		{elliptic.P384(), crypto.SHA384, 48},
+	} {
+		name := tt.curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, tt.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sig, err := SignDNSSEC(rand.Reader, priv, data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(sig) != 2*tt.size {
+			t.Fatalf("%s: signature field is %d bytes, want %d", name, len(sig), 2*tt.size)
+		}
+
+		if !VerifyDNSSEC(&priv.PublicKey, data, sig) {
+			t.Errorf("%s: signature rejected", name)
+		}
+
+		if VerifyDNSSEC(&priv.PublicKey, []byte("other"), sig) {
+			t.Errorf("%s: other data accepted", name)
+		}
+
+		// The field is r || s, big-endian, over the curve's hash of data.
+		der, err := DNSSECToDER(tt.curve, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, s, err := parseSignature(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Cmp(new(big.Int).SetBytes(sig[:tt.size])) != 0 || s.Cmp(new(big.Int).SetBytes(sig[tt.size:])) != 0 {
+			t.Errorf("%s: field is not r || s", name)
+		}
+
+		d := tt.hash.New()
+		d.Write(data)
+
+		if !VerifyASN1(&priv.PublicKey, d.Sum(nil), der) {
+			t.Errorf("%s: signature is not over %v of the data", name, tt.hash)
+		}
+
+		back, err := DERToDNSSEC(tt.curve, der)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(back, sig) {
+			t.Errorf("%s: DER round trip changed the field", name)
+		}
+
+		// Short components are left-padded to the fixed width.
+		small, err := encodeSignature(big.NewInt(1), big.NewInt(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		field, err := DERToDNSSEC(tt.curve, small)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := make([]byte, 2*tt.size)
+		want[tt.size-1], want[2*tt.size-1] = 1, 2
+
+		if !bytes.Equal(field, want) {
+			t.Errorf("%s: got %x", name, field)
+		}
+
+		for _, n := range []int{len(sig) - 1, len(sig) + 1} {
+			bad := make([]byte, n)
+			copy(bad, sig)
+
+			if _, err := DNSSECToDER(tt.curve, bad); err == nil {
+				t.Errorf("%s: %d byte field accepted", name, n)
+			}
+
+			if VerifyDNSSEC(&priv.PublicKey, data, bad) {
+				t.Errorf("%s: %d byte field verified", name, n)
+			}
+		}
+	}
+
+	p521, err := GenerateKey(rand.Reader, elliptic.P521())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SignDNSSEC(rand.Reader, p521, data); err == nil {
+		t.Error("P-521 DNSSEC signature made")
+	}
+
+	if _, err := DNSSECToDER(elliptic.P521(), make([]byte, 132)); err == nil {
+		t.Error("P-521 DNSSEC field accepted")
+	}
+}
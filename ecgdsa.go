@@ -1,10 +1,24 @@
+// Package ecgdsa implements the Elliptic Curve German Digital Signature
+// Algorithm (EC-GDSA) of BSI TR-03111 and ISO/IEC 14888-3.
+//
+// The NIST curves P-224 to P-521 and the Brainpool curves of RFC 5639 are
+// registered by default, along with the other curves in the registry; more
+// can be added with RegisterCurve. The Brainpool r1 curves and their
+// twisted t1 isomorphs with a = -3 are supported end to end: key
+// generation, PKCS#8, SEC 1 and SubjectPublicKeyInfo encoding with
+// compressed or uncompressed points, signing, verification and Validate.
+// Nothing assumes a = -3 (curveA recovers a from the base point) and all
+// of them have cofactor 1, which Validate computes rather than assumes.
+// JWK only names brainpoolP256r1, brainpoolP384r1 and brainpoolP512r1.
 package ecgdsa
 
 import (
+	"context"
 	"crypto"
 	"crypto/elliptic"
 	"crypto/subtle"
 	"errors"
+	"fmt"
 	"hash"
 	"io"
 	"math/big"
@@ -16,13 +30,27 @@ import (
 var (
 	ErrParametersNotSetUp = errors.New("ecgdsa: parameters not set up before generating key")
 	ErrInvalidASN1        = errors.New("ecgdsa: invalid ASN.1")
-	ErrInvalidSignerOpts  = errors.New("ecgdsa: opts must be *SignerOpts")
+	ErrKeyMismatch        = errors.New("ecgdsa: public key does not match private key")
+	ErrRandExhausted      = errors.New("ecgdsa: random source did not produce a usable value")
+	ErrNilRand            = errors.New("ecgdsa: random source is nil")
+	ErrHashUnavailable    = errors.New("ecgdsa: hash function is not available")
+
+	// Deprecated: PrivateKey.Sign accepts any crypto.SignerOpts and never
+	// returns ErrInvalidSignerOpts.
+	ErrInvalidSignerOpts = errors.New("ecgdsa: opts must be *SignerOpts")
 )
 
 var (
 	zero = big.NewInt(0)
 )
 
+// MaxRandAttempts bounds the number of candidates drawn by rejection
+// sampling when generating keys and nonces. With a working random source a
+// candidate is rejected with probability below one half, so the default is
+// only ever reached when the source is broken. Set it before use; it is
+// not safe to change concurrently with signing or key generation.
+var MaxRandAttempts = 1000
+
 type Hasher = func() hash.Hash
 
 // SignerOpts contains options for creating and verifying EC-GDSA signatures.
@@ -30,6 +58,23 @@ type SignerOpts struct {
 	Hash Hasher
 }
 
+// VerifyOpts contains options for verifying EC-GDSA signatures.
+type VerifyOpts struct {
+	// TolerantRaw accepts Bytes encoded signatures that are shorter than
+	// twice the curve size, as produced by encoders that strip leading
+	// zeros from r and s. The signature is split in half and both halves
+	// are left-padded. This is ambiguous when only one component was
+	// shortened, so it is off by default.
+	TolerantRaw bool
+
+	// TwinCurveDiagnostics makes VerifyWithOpts check, when a signature
+	// fails on a brainpool curve, whether it would verify on the
+	// isomorphic twin curve (r1 for t1 and the other way round). If so an
+	// error describing the likely mix-up is returned. The signature is
+	// still rejected.
+	TwinCurveDiagnostics bool
+}
+
 // HashFunc returns opts.Hash
 func (opts *SignerOpts) HashFunc() crypto.Hash {
 	return crypto.Hash(0)
@@ -47,7 +92,9 @@ type PublicKey struct {
 	X, Y *big.Int
 }
 
-// Equal reports whether pub and x have the same value.
+// Equal reports whether pub and x have the same value. Curves are
+// compared by their domain parameters, so keys on two distinct curve
+// values with the same parameters are equal.
 func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
 	xx, ok := x.(*PublicKey)
 	if !ok {
@@ -56,17 +103,41 @@ func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
 
 	return bigIntEqual(pub.X, xx.X) &&
 		bigIntEqual(pub.Y, xx.Y) &&
-		pub.Curve == xx.Curve
+		sameCurve(pub.Curve, xx.Curve)
 }
 
-// Verify asn.1 marshal data
+// SamePublicKey reports whether a and b represent the same point on the
+// same curve, regardless of how their coordinates were decoded.
+func SamePublicKey(a, b *PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if a.X == nil || a.Y == nil || b.X == nil || b.Y == nil {
+		return false
+	}
+
+	return a.Equal(b)
+}
+
+// Verify verifies an ASN.1 signature. Like PrivateKey.Sign, it hashes msg
+// with opts.Hash for *SignerOpts and otherwise treats msg as the hash value
+// produced by opts.HashFunc().
 func (pub *PublicKey) Verify(msg, sign []byte, opts crypto.SignerOpts) (bool, error) {
-	opt, ok := opts.(*SignerOpts)
-	if !ok {
-		return false, ErrInvalidSignerOpts
+	if opt, ok := opts.(*SignerOpts); ok {
+		return Verify(pub, opt.GetHash(), msg, sign), nil
+	}
+
+	if err := checkDigestSize(msg, opts); err != nil {
+		return false, err
 	}
 
-	return Verify(pub, opt.GetHash(), msg, sign), nil
+	r, s, err := parseSignature(sign)
+	if err != nil {
+		return false, nil
+	}
+
+	return verifyDigest(pub, msg, r, s), nil
 }
 
 // ec-gdsa PrivateKey
@@ -76,7 +147,7 @@ type PrivateKey struct {
 	D *big.Int
 }
 
-// Equal reports whether pub and x have the same value.
+// Equal reports whether priv and x have the same scalar and public key.
 func (priv *PrivateKey) Equal(x crypto.PrivateKey) bool {
 	xx, ok := x.(*PrivateKey)
 	if !ok {
@@ -87,23 +158,95 @@ func (priv *PrivateKey) Equal(x crypto.PrivateKey) bool {
 		priv.PublicKey.Equal(&xx.PublicKey)
 }
 
+// SamePrivateKey reports whether a and b hold the same scalar on the same
+// curve. The cached public coordinates are ignored, so a key with stale
+// coordinates still matches. The scalars are compared in constant time as
+// fixed-width byte strings of the length of the curve order.
+func SamePrivateKey(a, b *PrivateKey) bool {
+	if a == nil || b == nil || a.Curve == nil || a.D == nil || b.D == nil ||
+		!sameCurve(a.Curve, b.Curve) {
+		return false
+	}
+
+	size := BitsToBytes(a.Curve.Params().N.BitLen())
+	if BitsToBytes(a.D.BitLen()) > size || BitsToBytes(b.D.BitLen()) > size {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(a.D.FillBytes(make([]byte, size)), b.D.FillBytes(make([]byte, size))) == 1
+}
+
+// Zero overwrites the words backing priv.D with zeros and sets D to 0.
+// The key cannot sign afterwards. Copies of D made earlier, for example
+// by marshaling the key, are not affected. The parsing functions copy what
+// they need from their input, so the caller can wipe DER or PEM bytes as
+// soon as the key is parsed.
+func (priv *PrivateKey) Zero() {
+	if priv == nil || priv.D == nil {
+		return
+	}
+
+	words := priv.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+
+	priv.D.SetInt64(0)
+}
+
 // Public returns the public key corresponding to priv.
 func (priv *PrivateKey) Public() crypto.PublicKey {
 	return &priv.PublicKey
 }
 
-// crypto.Signer
+// Sign implements crypto.Signer and returns an ASN.1 signature.
+//
+// With *SignerOpts, digest is the message itself and is hashed with
+// opts.Hash, as before. With any other opts, such as a crypto.Hash passed
+// by standard library code, digest must already be the hash value: it is
+// signed as is, and rejected if its length does not match opts.HashFunc().
 func (priv *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
-	opt, ok := opts.(*SignerOpts)
-	if !ok {
-		return nil, ErrInvalidSignerOpts
+	if opt, ok := opts.(*SignerOpts); ok {
+		return Sign(rand, priv, opt.GetHash(), digest)
+	}
+
+	if err := checkDigestSize(digest, opts); err != nil {
+		return nil, err
+	}
+
+	r, s, err := signDigest(rand, priv, digest)
+	if err != nil {
+		return nil, err
 	}
 
-	return Sign(rand, priv, opt.GetHash(), digest)
+	return encodeSignature(r, s)
+}
+
+// checkDigestSize checks a pre-hashed digest against opts.HashFunc().
+func checkDigestSize(digest []byte, opts crypto.SignerOpts) error {
+	if opts == nil {
+		return nil
+	}
+
+	if h := opts.HashFunc(); h != 0 && len(digest) != h.Size() {
+		return fmt.Errorf("ecgdsa: digest is %d bytes, %v needs %d", len(digest), h, h.Size())
+	}
+
+	return nil
 }
 
-// Generate the PrivateKey
+// Generate the PrivateKey. A nil random source fails with ErrNilRand and
+// a source that cannot supply enough bytes fails with an error wrapping
+// the read error.
 func GenerateKey(random io.Reader, c elliptic.Curve) (*PrivateKey, error) {
+	if c == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if err := fipsCheckCurve(c); err != nil {
+		return nil, err
+	}
+
 	d, err := randFieldElement(random, c)
 	if err != nil {
 		return nil, err
@@ -119,23 +262,33 @@ func GenerateKey(random io.Reader, c elliptic.Curve) (*PrivateKey, error) {
 	return priv, nil
 }
 
-// New a PrivateKey from privatekey data
+// New a PrivateKey from privatekey data, the big-endian bytes of D. D
+// must be in [1, N-1].
 func NewPrivateKey(curve elliptic.Curve, k []byte) (*PrivateKey, error) {
-	d := new(big.Int).SetBytes(k)
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
 
-	one := new(big.Int).SetInt64(1)
+	return NewPrivateKeyFromScalar(curve, new(big.Int).SetBytes(k))
+}
 
-	n := new(big.Int).Sub(curve.Params().N, one)
-	if d.Cmp(n) >= 0 {
-		return nil, errors.New("cryptobin/ecgdsa: privateKey's D is overflow")
+// NewPrivateKeyFromScalar returns the private key with scalar d on curve,
+// filling in the public point with XY. It is the inverse of reading
+// priv.D and is meant for scalars obtained outside of GenerateKey, such as
+// from a key derivation function. d must be in [1, N-1]; it is copied.
+func NewPrivateKeyFromScalar(curve elliptic.Curve, d *big.Int) (*PrivateKey, error) {
+	if curve == nil || d == nil {
+		return nil, ErrParametersNotSetUp
 	}
 
-	dInv := fermatInverse(d, curve.Params().N)
+	if d.Sign() <= 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, ErrInvalidPrivateKeyValue
+	}
 
 	priv := new(PrivateKey)
 	priv.PublicKey.Curve = curve
-	priv.D = d
-	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(dInv.Bytes())
+	priv.D = new(big.Int).Set(d)
+	priv.PublicKey.X, priv.PublicKey.Y = XY(priv.D, curve)
 
 	return priv, nil
 }
@@ -146,11 +299,21 @@ func PrivateKeyTo(key *PrivateKey) []byte {
 	return key.D.FillBytes(privateKey)
 }
 
-// New a PublicKey from publicKey data
+// New a PublicKey from publicKey data, a SEC 1 point in compressed or
+// uncompressed form. The point must be on curve.
 func NewPublicKey(curve elliptic.Curve, k []byte) (*PublicKey, error) {
-	x, y := elliptic.Unmarshal(curve, k)
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	byteLen := (curve.Params().BitSize + 7) / 8
+	if len(k) != 1+byteLen && len(k) != 1+2*byteLen {
+		return nil, fmt.Errorf("%w: point is %d bytes, want %d or %d", ErrInvalidPublicKey, len(k), 1+byteLen, 1+2*byteLen)
+	}
+
+	x, y := unmarshalPoint(curve, k)
 	if x == nil || y == nil {
-		return nil, errors.New("cryptobin/ecgdsa: incorrect public key")
+		return nil, fmt.Errorf("%w: failed to unmarshal point", ErrInvalidPublicKey)
 	}
 
 	pub := &PublicKey{
@@ -167,6 +330,12 @@ func PublicKeyTo(key *PublicKey) []byte {
 	return elliptic.Marshal(key.Curve, key.X, key.Y)
 }
 
+// Bytes returns the uncompressed SEC 1 encoding of pub, as accepted by
+// NewPublicKey.
+func (pub *PublicKey) Bytes() []byte {
+	return PublicKeyTo(pub)
+}
+
 // Sign data returns the ASN.1 encoded signature.
 func Sign(rand io.Reader, priv *PrivateKey, h Hasher, data []byte) (sig []byte, err error) {
 	r, s, err := SignToRS(rand, priv, h, data)
@@ -179,6 +348,13 @@ func Sign(rand io.Reader, priv *PrivateKey, h Hasher, data []byte) (sig []byte,
 
 // Verify verifies the ASN.1 encoded signature, sig, M, of hash using the
 // public key, pub. Its return value records whether the signature is valid.
+//
+// Only minimal DER is accepted, and EC-GDSA has no ECDSA-style (r, N-s)
+// twin: replacing s with N-s gives a signature that does not verify, so
+// there is no low-S form to normalize to. Randomized signing still
+// produces a different valid signature each time the signer signs, so
+// systems that must identify a signed message should key on the message,
+// not on the signature bytes.
 func Verify(pub *PublicKey, h Hasher, data, sig []byte) bool {
 	r, s, err := parseSignature(sig)
 	if err != nil {
@@ -188,6 +364,75 @@ func Verify(pub *PublicKey, h Hasher, data, sig []byte) bool {
 	return VerifyWithRS(pub, h, data, r, s)
 }
 
+// SignWithHash hashes msg with h and signs the digest, returning an ASN.1
+// encoded signature. Pick h to match the curve, for example SHA-256 for
+// P-256, SHA-384 for P-384 and SHA-512 for P-521. It is the same as Sign.
+//
+// A digest longer than N.BitLen() bits is truncated to its leftmost
+// N.BitLen() bits, as BSI TR-03111 requires, for example SHA-512 on P-256
+// keeps 256 bits. SHA-512 on P-521 is not truncated, since 512 bits is
+// shorter than the 521-bit order. Earlier versions truncated to whole
+// bytes instead, which differs when N.BitLen() is not a multiple of 8 and
+// the digest has at least (N.BitLen()+7)/8 bytes, such as a 66-byte
+// digest on P-521: signatures over such digests made before and after the
+// change do not verify with the other version.
+func SignWithHash(rand io.Reader, priv *PrivateKey, h func() hash.Hash, msg []byte) ([]byte, error) {
+	return Sign(rand, priv, h, msg)
+}
+
+// VerifyWithHash verifies an ASN.1 encoded signature made by SignWithHash
+// with the same h. It is the same as Verify.
+func VerifyWithHash(pub *PublicKey, h func() hash.Hash, msg, sig []byte) bool {
+	return Verify(pub, h, msg, sig)
+}
+
+// SignASN1 signs the hash value hash, the result of hashing a larger
+// message, and returns the ASN.1 encoded signature. It mirrors
+// ecdsa.SignASN1, but the EC-GDSA signing equation differs from ECDSA's,
+// so its signatures do not verify with crypto/ecdsa and ECDSA signatures
+// do not verify with VerifyASN1.
+func SignASN1(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := signDigest(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSignature(r, s)
+}
+
+// VerifyASN1 verifies the ASN.1 encoded signature sig of the hash value
+// hash. It mirrors ecdsa.VerifyASN1; see SignASN1 for how the signatures
+// differ.
+func VerifyASN1(pub *PublicKey, hash, sig []byte) bool {
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		return false
+	}
+
+	return verifyDigest(pub, hash, r, s)
+}
+
+// VerifyWithOpts verifies the ASN.1 encoded signature using opts. A nil
+// opts behaves like Verify. The error is non-nil only when a diagnostic
+// enabled in opts has something to report; it never makes an invalid
+// signature valid.
+func VerifyWithOpts(pub *PublicKey, h Hasher, data, sig []byte, opts *VerifyOpts) (bool, error) {
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		return false, nil
+	}
+
+	if VerifyWithRS(pub, h, data, r, s) {
+		return true, nil
+	}
+
+	if opts != nil && opts.TwinCurveDiagnostics {
+		return false, twinCurveDiagnostic(pub, h, data, r, s)
+	}
+
+	return false, nil
+}
+
 func encodeSignature(r, s *big.Int) ([]byte, error) {
 	var b cryptobyte.Builder
 	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
@@ -235,18 +480,46 @@ func SignBytes(rand io.Reader, priv *PrivateKey, h Hasher, data []byte) (sig []b
 
 // Verify verifies the Bytes encoded signature
 func VerifyBytes(pub *PublicKey, h Hasher, data, sig []byte) bool {
-	byteLen := (pub.Curve.Params().BitSize + 7) / 8
+	return VerifyBytesWithOpts(pub, h, data, sig, nil)
+}
 
-	if len(sig) != 2*byteLen {
+// VerifyBytesWithOpts verifies the Bytes encoded signature using opts.
+// A nil opts behaves like VerifyBytes.
+func VerifyBytesWithOpts(pub *PublicKey, h Hasher, data, sig []byte, opts *VerifyOpts) bool {
+	if pub == nil || pub.Curve == nil {
 		return false
 	}
 
-	r := new(big.Int).SetBytes(sig[:byteLen])
-	s := new(big.Int).SetBytes(sig[byteLen:])
+	byteLen := (pub.Curve.Params().BitSize + 7) / 8
+
+	tolerant := opts != nil && opts.TolerantRaw
+
+	r, s, ok := decodeBytesSignature(sig, byteLen, tolerant)
+	if !ok {
+		return false
+	}
 
 	return VerifyWithRS(pub, h, data, r, s)
 }
 
+// decodeBytesSignature splits a r || s signature of byteLen sized
+// components. In tolerant mode an even length shorter than 2*byteLen is
+// split in half and each half is left-padded back to byteLen.
+func decodeBytesSignature(sig []byte, byteLen int, tolerant bool) (r, s *big.Int, ok bool) {
+	if len(sig) != 2*byteLen {
+		if !tolerant || len(sig) == 0 || len(sig) > 2*byteLen || len(sig)%2 != 0 {
+			return nil, nil, false
+		}
+
+		byteLen = len(sig) / 2
+	}
+
+	r = new(big.Int).SetBytes(sig[:byteLen])
+	s = new(big.Int).SetBytes(sig[byteLen:])
+
+	return r, s, true
+}
+
 /**
  *| IUF - EC-GDSA signature
  *|
@@ -263,48 +536,99 @@ func VerifyBytes(pub *PublicKey, h Hasher, data, sig []byte) bool {
  *
  */
 func SignToRS(rand io.Reader, priv *PrivateKey, hashFunc Hasher, msg []byte) (r, s *big.Int, err error) {
-	if priv == nil || priv.Curve == nil ||
-		priv.X == nil || priv.Y == nil ||
-		priv.D == nil || !priv.Curve.IsOnCurve(priv.X, priv.Y) {
+	if priv == nil || priv.Curve == nil {
 		return nil, nil, ErrParametersNotSetUp
 	}
 
-	h := hashFunc()
-
-	curve := priv.Curve
-	curveParams := curve.Params()
-	n := curveParams.N
+	if err := fipsCheckHash(hashFunc, priv.Curve); err != nil {
+		return nil, nil, err
+	}
 
-	w := (n.BitLen() + 7) / 8
-	hsize := h.Size()
-	d := priv.D
+	h := hashFunc()
 
 	/* 1. Compute h = H(m) */
 	h.Write(msg)
-	eBuf := h.Sum(nil)
 
-	rshift := 0
-	if hsize > w {
-		rshift = (hsize - w) * 8
+	return signDigest(rand, priv, h.Sum(nil))
+}
+
+// SignContext signs the hash value hash like PrivateKey.Sign with a
+// pre-hashed digest and returns the ASN.1 encoded signature. ctx is
+// checked before each scalar multiplication, and ctx.Err() is returned if
+// it is done.
+func SignContext(ctx context.Context, rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	if priv == nil || priv.Curve == nil {
+		return nil, ErrParametersNotSetUp
 	}
 
-	e := new(big.Int).SetBytes(eBuf)
-	if rshift > 0 {
-		e.Rsh(e, uint(rshift))
+	r, s, err := signDigestWithNonce(priv, hash, func() (*big.Int, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return randFieldElement(rand, priv.Curve)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return encodeSignature(r, s)
+}
+
+// signDigest runs steps 2 to 9 of the signature on the hash value digest.
+func signDigest(rand io.Reader, priv *PrivateKey, digest []byte) (r, s *big.Int, err error) {
+	return signDigestWithNonce(priv, digest, func() (*big.Int, error) {
+		return randFieldElement(rand, priv.Curve)
+	})
+}
+
+// signDigestWithNonce is signDigest with the nonces k drawn from nonce.
+func signDigestWithNonce(priv *PrivateKey, digest []byte, nonce func() (*big.Int, error)) (r, s *big.Int, err error) {
+	if priv == nil || priv.Curve == nil {
+		return nil, nil, ErrParametersNotSetUp
+	}
+
+	return signDigestWithBaseMult(priv, digest, nonce, priv.Curve.ScalarBaseMult)
+}
+
+// signDigestWithBaseMult is signDigestWithNonce with kG computed by
+// baseMult.
+func signDigestWithBaseMult(priv *PrivateKey, digest []byte, nonce func() (*big.Int, error), baseMult func(k []byte) (x, y *big.Int)) (r, s *big.Int, err error) {
+	if priv == nil || priv.Curve == nil ||
+		priv.X == nil || priv.Y == nil ||
+		priv.D == nil || priv.D.Sign() <= 0 ||
+		!priv.Curve.IsOnCurve(priv.X, priv.Y) {
+		return nil, nil, ErrParametersNotSetUp
+	}
+
+	if err := fipsCheckCurve(priv.Curve); err != nil {
+		return nil, nil, err
+	}
+
+	curve := priv.Curve
+	curveParams := curve.Params()
+	n := curveParams.N
+
+	d := priv.D
+
 	// 2: e = q - (h mod q) (except when h is 0).
-	e = e.Mod(e, n)
+	e := hashToInt(digest, n)
 	e.Mod(e.Neg(e), n)
 
+	attempts := 0
+
 Retry:
-	k, err := randFieldElement(rand, priv.Curve)
+	if attempts++; attempts > MaxRandAttempts {
+		return nil, nil, ErrRandExhausted
+	}
+
+	k, err := nonce()
 	if err != nil {
 		return
 	}
 
 	// 4: Compute W = kG = (Wx, Wy) */
-	x1, _ := curve.ScalarBaseMult(k.Bytes())
+	x1, _ := baseMult(k.Bytes())
 
 	// 5. Compute r = Wx mod q */
 	r = new(big.Int)
@@ -344,41 +668,38 @@ Retry:
  *
  */
 func VerifyWithRS(pub *PublicKey, hashFunc Hasher, data []byte, r, s *big.Int) bool {
-	if pub == nil || pub.Curve == nil ||
-		pub.X == nil || pub.Y == nil ||
-		!pub.Curve.IsOnCurve(pub.X, pub.Y) {
-		return false
-	}
-
-	if r.Sign() <= 0 || s.Sign() <= 0 {
+	if pub == nil || fipsCheckHash(hashFunc, pub.Curve) != nil {
 		return false
 	}
 
 	h := hashFunc()
 
-	curve := pub.Curve
-	curveParams := pub.Curve.Params()
-	n := curveParams.N
-
-	w := (n.BitLen() + 7) / 8
-	hsize := h.Size()
-
-	/* 1. Compute h = H(m) */
+	/* 2. Compute h = H(m) */
 	h.Write(data)
-	eBuf := h.Sum(nil)
 
-	rshift := 0
-	if hsize > w {
-		rshift = (hsize - w) * 8
+	return verifyDigest(pub, h.Sum(nil), r, s)
+}
+
+// verifyDigest runs the verification on the hash value digest. A public
+// key that is not a valid point of its curve never verifies, so an
+// off-curve key cannot be used for an invalid-curve attack.
+func verifyDigest(pub *PublicKey, digest []byte, r, s *big.Int) bool {
+	if !isValidPublicPoint(pub) || fipsCheckCurve(pub.Curve) != nil {
+		return false
 	}
 
-	e := new(big.Int).SetBytes(eBuf)
-	if rshift > 0 {
-		e.Rsh(e, uint(rshift))
+	// r and s must be in [1, N-1] for this curve before any point
+	// arithmetic; a signature made on a larger curve fails here.
+	if r == nil || s == nil || !checkSignatureRange(pub.Curve, r, s) {
+		return false
 	}
 
+	curve := pub.Curve
+	curveParams := pub.Curve.Params()
+	n := curveParams.N
+
 	/* 3. Compute e by converting h to an integer and reducing it mod q */
-	e = e.Mod(e, n)
+	e := hashToInt(digest, n)
 
 	/* 4. Compute u = (r^-1)e mod q */
 	rinv := new(big.Int).ModInverse(r, n)
@@ -400,19 +721,81 @@ func VerifyWithRS(pub *PublicKey, hashFunc Hasher, data []byte, r, s *big.Int) b
 	return r.Cmp(rPrime) == 0
 }
 
+// isValidPublicPoint reports whether pub is a point of its curve other
+// than the point at infinity, with both coordinates reduced mod P.
+func isValidPublicPoint(pub *PublicKey) bool {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return false
+	}
+
+	if pub.X.Sign() == 0 && pub.Y.Sign() == 0 {
+		return false
+	}
+
+	p := pub.Curve.Params().P
+	if pub.X.Sign() < 0 || pub.Y.Sign() < 0 || pub.X.Cmp(p) >= 0 || pub.Y.Cmp(p) >= 0 {
+		return false
+	}
+
+	return pub.Curve.IsOnCurve(pub.X, pub.Y)
+}
+
+// hashToInt converts a hash value to an integer mod n. A hash longer than
+// the curve order is truncated to its leftmost n.BitLen() bits.
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+
+	e := new(big.Int).SetBytes(hash)
+
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+
+	return e.Mod(e, n)
+}
+
+// XY returns the EC-GDSA public point of the private scalar D on c, which
+// is D⁻¹·G rather than the D·G of ECDSA. D must be in [1, N-1]; use
+// PublicPointFromScalar to have that checked.
 func XY(D *big.Int, c elliptic.Curve) (X, Y *big.Int) {
 	dInv := fermatInverse(D, c.Params().N)
 	return c.ScalarBaseMult(dInv.Bytes())
 }
 
+// PublicPointFromScalar returns the public point of the private scalar d
+// on curve. As EC-GDSA keys are defined, the point is d⁻¹·G, not d·G. d
+// must be in [1, N-1]; otherwise the error is ErrInvalidPrivateKeyValue.
+func PublicPointFromScalar(curve elliptic.Curve, d *big.Int) (x, y *big.Int, err error) {
+	if curve == nil || d == nil {
+		return nil, nil, ErrParametersNotSetUp
+	}
+
+	if d.Sign() <= 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, nil, fmt.Errorf("%w: private scalar out of range", ErrInvalidPrivateKeyValue)
+	}
+
+	x, y = XY(d, curve)
+
+	return x, y, nil
+}
+
 // randFieldElement returns a random element of the order of the given
 // curve using the procedure given in FIPS 186-4, Appendix B.5.2.
+// It gives up with ErrRandExhausted after MaxRandAttempts candidates.
 func randFieldElement(rand io.Reader, c elliptic.Curve) (k *big.Int, err error) {
-	for {
+	if rand == nil {
+		return nil, ErrNilRand
+	}
+
+	for i := 0; i < MaxRandAttempts; i++ {
 		N := c.Params().N
 		b := make([]byte, (N.BitLen()+7)/8)
 		if _, err = io.ReadFull(rand, b); err != nil {
-			return
+			return nil, fmt.Errorf("ecgdsa: reading from random source: %w", err)
 		}
 
 		if excess := len(b)*8 - N.BitLen(); excess > 0 {
@@ -424,6 +807,8 @@ func randFieldElement(rand io.Reader, c elliptic.Curve) (k *big.Int, err error)
 			return
 		}
 	}
+
+	return nil, ErrRandExhausted
 }
 
 func fermatInverse(a, N *big.Int) *big.Int {
@@ -432,6 +817,23 @@ func fermatInverse(a, N *big.Int) *big.Int {
 	return new(big.Int).Exp(a, nMinus2, N)
 }
 
+// sameCurve reports whether a and b are the same curve, either the same
+// value or distinct values with the same domain parameters.
+func sameCurve(a, b elliptic.Curve) bool {
+	if a == b {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	pa, pb := a.Params(), b.Params()
+
+	return pa.P.Cmp(pb.P) == 0 && pa.N.Cmp(pb.N) == 0 && pa.B.Cmp(pb.B) == 0 &&
+		pa.Gx.Cmp(pb.Gx) == 0 && pa.Gy.Cmp(pb.Gy) == 0 && pa.BitSize == pb.BitSize
+}
+
 // bigIntEqual reports whether a and b are equal leaking only their bit length
 // through timing side-channels.
 func bigIntEqual(a, b *big.Int) bool {
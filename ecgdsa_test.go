@@ -0,0 +1,725 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) *PrivateKey {
+	t.Helper()
+
+	priv, err := GenerateKey(rand.Reader, elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return priv
+}
+
+func TestNilRand(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+	msg := []byte("message")
+
+	precomputed, err := NewPrecomputedSigner(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GenerateKey", func() error {
+			_, err := GenerateKey(nil, elliptic.P256())
+			return err
+		}},
+		{"GenerateVanityKey", func() error {
+			_, err := GenerateVanityKey(elliptic.P256(), []byte{2}, nil, 10)
+			return err
+		}},
+		{"PrivateKey.Sign", func() error {
+			_, err := priv.Sign(nil, hash[:], crypto.SHA256)
+			return err
+		}},
+		{"PrivateKey.SignBatch", func() error {
+			_, err := priv.SignBatch(nil, [][]byte{hash[:], hash[:]})
+			return err
+		}},
+		{"Sign", func() error {
+			_, err := Sign(nil, priv, sha256.New, msg)
+			return err
+		}},
+		{"SignASN1", func() error {
+			_, err := SignASN1(nil, priv, hash[:])
+			return err
+		}},
+		{"SignBytes", func() error {
+			_, err := SignBytes(nil, priv, sha256.New, msg)
+			return err
+		}},
+		{"SignContext", func() error {
+			_, err := SignContext(context.Background(), nil, priv, hash[:])
+			return err
+		}},
+		{"SignRaw", func() error {
+			_, err := SignRaw(nil, priv, hash[:])
+			return err
+		}},
+		{"SignRawRecoverable", func() error {
+			_, _, err := SignRawRecoverable(nil, priv, hash[:])
+			return err
+		}},
+		{"SignWithCodec", func() error {
+			_, err := SignWithCodec(nil, priv, sha256.New, msg, RawCodec)
+			return err
+		}},
+		{"SignCurveBound", func() error {
+			_, err := SignCurveBound(nil, priv, sha256.New, msg)
+			return err
+		}},
+		{"SignDNSSEC", func() error {
+			_, err := SignDNSSEC(nil, priv, msg)
+			return err
+		}},
+		{"SignWithTimestamp", func() error {
+			_, err := SignWithTimestamp(nil, priv, sha256.New, msg, time.Now())
+			return err
+		}},
+		{"SignSalted", func() error {
+			_, err := SignSalted(nil, priv, msg, crypto.SHA256)
+			return err
+		}},
+		{"SignMerkleRoot", func() error {
+			_, _, err := SignMerkleRoot(nil, priv, [][]byte{msg}, crypto.SHA256)
+			return err
+		}},
+		{"PrecomputedSigner.Sign", func() error {
+			_, err := precomputed.Sign(nil, hash[:])
+			return err
+		}},
+		{"SigningStream.Finish", func() error {
+			_, err := NewSigningStream(priv, sha256.New).Finish(nil)
+			return err
+		}},
+		{"CreateSelfSignedCertificate", func() error {
+			_, err := CreateSelfSignedCertificate(nil, pkix.Name{CommonName: "test"}, priv, time.Hour, crypto.SHA256)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNilRand) {
+				t.Errorf("got error %v, want ErrNilRand", err)
+			}
+		})
+	}
+}
+
+// TestSignWithHashP521 checks a P-521 signature over SHA-512("sample")
+// with a fixed nonce, computed independently from the EC-GDSA equations.
+func TestSignWithHashP521(t *testing.T) {
+	d, _ := new(big.Int).SetString("f937bffbbd5db79de9e3368292c956735dc3d2ba25a38b96712f83c6c04b02f63dfab5dc64efaa4c9ed2199501fee79805f6479daf107a63feb592427b6050247b", 16)
+	k, _ := new(big.Int).SetString("3ae5b632940d021a565450c213e04dc4ef993d5bc504124f15858f5c34e8253f24087985df4a83d39c9bb47c990a9180a101686280c19a72d90a8b49fe2063c034", 16)
+	wantX, _ := new(big.Int).SetString("17e513eee86339e74288e734b091f7c2c7622d12a7eeef87181fa70f4b4a69c66b5596110d4ce8b0b67636494721d4bdccf85427382864523c9e4a9b571bf468659", 16)
+	wantR, _ := new(big.Int).SetString("1d3a923e7249855afb060dc771d6f08743a422a5eb79f9b2539c0fd31af392dc3d10834ba23e75aba629fecd3e7951fdb96843dcf3814c9dc4cf3e0feaadc8c39d3", 16)
+	wantS, _ := new(big.Int).SetString("1f6ac293d67c24fb3a95f238f007ea9e63bfdaa1189e82f07766fe230eb23ddc740b4b777a424c35948370dab4c0a612af4f78d268a71c944efab75509ff0154359", 16)
+
+	priv, err := NewPrivateKeyFromScalar(elliptic.P521(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if priv.X.Cmp(wantX) != 0 {
+		t.Fatal("wrong public key")
+	}
+
+	msg := []byte("sample")
+	digest := sha512.Sum512(msg)
+
+	r, s, err := signDigestWithBaseMult(priv, digest[:], func() (*big.Int, error) {
+		return k, nil
+	}, priv.Curve.ScalarBaseMult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Cmp(wantR) != 0 || s.Cmp(wantS) != 0 {
+		t.Errorf("got (%x, %x)", r, s)
+	}
+
+	sig, err := encodeSignature(wantR, wantS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !VerifyWithHash(&priv.PublicKey, sha512.New, msg, sig) {
+		t.Error("vector does not verify")
+	}
+
+	if VerifyWithHash(&priv.PublicKey, sha512.New, []byte("other"), sig) {
+		t.Error("vector verifies for another message")
+	}
+
+	sig, err = SignWithHash(rand.Reader, priv, sha512.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !VerifyWithHash(&priv.PublicKey, sha512.New, msg, sig) {
+		t.Error("SignWithHash signature does not verify")
+	}
+}
+
+func TestHashToIntTruncation(t *testing.T) {
+	n := elliptic.P521().Params().N
+
+	// A 66-byte digest keeps its leftmost 521 bits, not all 528.
+	want, _ := new(big.Int).SetString("5ae79787c40d069948033feb708f65a2fc44a36477663b851449048e16ec79bf6", 16)
+	if got := hashToInt(bytes.Repeat([]byte{0xff}, 66), n); got.Cmp(want) != 0 {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	// SHA-512 on P-256 keeps the leftmost 256 bits.
+	digest := sha512.Sum512([]byte("sample"))
+	n = elliptic.P256().Params().N
+	if got, want := hashToInt(digest[:], n), new(big.Int).SetBytes(digest[:32]); got.Cmp(want.Mod(want, n)) != 0 {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestPublicPointFromScalarRange(t *testing.T) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	for _, d := range []*big.Int{big.NewInt(0), big.NewInt(-1), n, new(big.Int).Add(n, big.NewInt(1))} {
+		if _, _, err := PublicPointFromScalar(curve, d); !errors.Is(err, ErrInvalidPrivateKeyValue) {
+			t.Errorf("d = %v: got %v, want ErrInvalidPrivateKeyValue", d, err)
+		}
+	}
+
+	x, y, err := PublicPointFromScalar(curve, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Cmp(curve.Params().Gx) != 0 || y.Cmp(curve.Params().Gy) != 0 {
+		t.Error("d = 1 does not give G")
+	}
+}
+
+func TestVerifySignatureRange(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+	n := priv.Curve.Params().N
+
+	r, s, err := signDigest(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !verifyDigest(&priv.PublicKey, hash[:], r, s) {
+		t.Fatal("valid signature rejected")
+	}
+
+	for _, tt := range []struct {
+		name string
+		r, s *big.Int
+	}{
+		{"r+N", new(big.Int).Add(r, n), s},
+		// s + N reduces to s, so only the range check rejects it.
+		{"s+N", r, new(big.Int).Add(s, n)},
+		{"r=N", n, s},
+		{"s=N", r, n},
+		{"r=0", new(big.Int), s},
+		{"s=0", r, new(big.Int)},
+		{"r<0", new(big.Int).Neg(r), s},
+	} {
+		if verifyDigest(&priv.PublicKey, hash[:], tt.r, tt.s) {
+			t.Errorf("%s: accepted", tt.name)
+		}
+
+		if sig, err := encodeSignature(tt.r, tt.s); err == nil && VerifyASN1(&priv.PublicKey, hash[:], sig) {
+			t.Errorf("%s: accepted by VerifyASN1", tt.name)
+		}
+	}
+
+	// A P-384 signature has components up to 384 bits.
+	priv384, err := GenerateKey(rand.Reader, elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := SignASN1(rand.Reader, priv384, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if VerifyASN1(&priv.PublicKey, hash[:], sig) {
+		t.Error("P-384 signature accepted by a P-256 key")
+	}
+}
+
+// TestVerifyInvalidPublicKey checks that Verify and verifyDigest reject a
+// public key that is not a point of its curve, before the point reaches
+// the curve arithmetic, which panics on such input for the NIST curves.
+func TestVerifyInvalidPublicKey(t *testing.T) {
+	priv := testKey(t)
+	p := priv.Curve.Params().P
+	msg := []byte("message")
+	hash := sha256.Sum256(msg)
+
+	sig, err := Sign(rand.Reader, priv, sha256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		x, y *big.Int
+	}{
+		{"y+1", priv.X, new(big.Int).Add(priv.Y, big.NewInt(1))},
+		{"x+P", new(big.Int).Add(priv.X, p), priv.Y},
+		{"y+P", priv.X, new(big.Int).Add(priv.Y, p)},
+		{"-x", new(big.Int).Neg(priv.X), priv.Y},
+		{"infinity", new(big.Int), new(big.Int)},
+		{"nil y", priv.X, nil},
+	} {
+		pub := &PublicKey{Curve: priv.Curve, X: tt.x, Y: tt.y}
+
+		if Verify(pub, sha256.New, msg, sig) {
+			t.Errorf("%s: accepted by Verify", tt.name)
+		}
+
+		if verifyDigest(pub, hash[:], r, s) {
+			t.Errorf("%s: accepted by verifyDigest", tt.name)
+		}
+
+		if VerifyASN1(pub, hash[:], sig) {
+			t.Errorf("%s: accepted by VerifyASN1", tt.name)
+		}
+	}
+}
+
+func TestSamePublicKeyEncodings(t *testing.T) {
+	priv, other := testKey(t), testKey(t)
+
+	uncompressed, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, err := MarshalCompressedPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := ParsePublicKey(uncompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ParsePublicKey(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !SamePublicKey(a, b) || !SamePublicKey(b, &priv.PublicKey) {
+		t.Error("compressed and uncompressed encodings of the same key differ")
+	}
+
+	if SamePublicKey(a, &other.PublicKey) {
+		t.Error("different keys compare equal")
+	}
+
+	// A point with the same coordinates on another curve is another key.
+	clone := *elliptic.P256().Params()
+	clone.Gx, clone.Gy = elliptic.P256().Double(clone.Gx, clone.Gy)
+
+	if SamePublicKey(a, &PublicKey{Curve: &clone, X: a.X, Y: a.Y}) {
+		t.Error("keys on different curves compare equal")
+	}
+
+	if !SamePublicKey(nil, nil) || SamePublicKey(a, nil) || SamePublicKey(a, &PublicKey{Curve: a.Curve}) {
+		t.Error("wrong result for nil or incomplete keys")
+	}
+}
+
+func TestSamePrivateKey(t *testing.T) {
+	priv, other := testKey(t), testKey(t)
+
+	// stale keeps the scalar of priv with the public coordinates of other.
+	stale := *priv
+	stale.X, stale.Y = other.X, other.Y
+
+	params := *elliptic.P256().Params()
+	copied := *priv
+	copied.Curve = &params
+
+	p384 := *priv
+	p384.Curve = elliptic.P384()
+
+	wide := *priv
+	wide.D = new(big.Int).Add(priv.D, new(big.Int).Lsh(big.NewInt(1), 256))
+
+	for _, tt := range []struct {
+		name string
+		a, b *PrivateKey
+		want bool
+	}{
+		{"same key", priv, priv, true},
+		{"parsed copy", priv, &PrivateKey{PublicKey: priv.PublicKey, D: new(big.Int).Set(priv.D)}, true},
+		{"stale public key", priv, &stale, true},
+		{"same parameters", priv, &copied, true},
+		{"other scalar", priv, other, false},
+		{"other curve", priv, &p384, false},
+		{"scalar wider than the order", priv, &wide, false},
+		{"no scalar", priv, &PrivateKey{PublicKey: priv.PublicKey}, false},
+		{"nil", priv, nil, false},
+		{"both nil", nil, nil, false},
+	} {
+		if got := SamePrivateKey(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+
+		if got := SamePrivateKey(tt.b, tt.a); got != tt.want {
+			t.Errorf("%s, swapped: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Equal also compares the public coordinates.
+	if priv.Equal(&stale) {
+		t.Error("Equal ignores stale public coordinates")
+	}
+}
+
+func TestPrivateKeyCryptoSigner(t *testing.T) {
+	priv := testKey(t)
+
+	var signer crypto.Signer = priv
+	if pub, ok := signer.Public().(*PublicKey); !ok || !pub.Equal(&priv.PublicKey) {
+		t.Errorf("Public returned %T", signer.Public())
+	}
+
+	msg := []byte("message")
+	digest256 := sha256.Sum256(msg)
+	digest384 := sha512.Sum384(msg)
+
+	for _, tt := range []struct {
+		name   string
+		digest []byte
+		opts   crypto.SignerOpts
+		ok     bool
+	}{
+		{"SHA-256", digest256[:], crypto.SHA256, true},
+		{"SHA-384", digest384[:], crypto.SHA384, true},
+		{"no hash", digest256[:], crypto.Hash(0), true},
+		{"nil opts", digest384[:], nil, true},
+		{"SHA-384 digest for SHA-256", digest384[:], crypto.SHA256, false},
+		{"SHA-256 digest for SHA-512", digest256[:], crypto.SHA512, false},
+	} {
+		sig, err := signer.Sign(rand.Reader, tt.digest, tt.opts)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got %v", tt.name, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !VerifyASN1(&priv.PublicKey, tt.digest, sig) {
+			t.Errorf("%s: signature does not verify", tt.name)
+		}
+
+		if _, _, err := parseSignature(sig); err != nil {
+			t.Errorf("%s: signature is not DER: %v", tt.name, err)
+		}
+	}
+
+	// With *SignerOpts the input is the message, hashed by Sign.
+	sig, err := signer.Sign(rand.Reader, msg, &SignerOpts{Hash: sha256.New})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Verify(&priv.PublicKey, sha256.New, msg, sig) || !VerifyASN1(&priv.PublicKey, digest256[:], sig) {
+		t.Error("SignerOpts signature does not verify")
+	}
+}
+
+func TestKeyEqual(t *testing.T) {
+	priv, other := testKey(t), testKey(t)
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := ParsePrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A distinct curve value with the P-256 parameters.
+	params := *elliptic.P256().Params()
+	copied := *priv
+	copied.Curve = &params
+
+	p384 := *priv
+	p384.Curve = elliptic.P384()
+
+	stale := *priv
+	stale.X, stale.Y = other.X, other.Y
+
+	ecdsaKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: priv.Curve, X: priv.X, Y: priv.Y},
+		D:         priv.D,
+	}
+
+	for _, tt := range []struct {
+		name string
+		x    crypto.PublicKey
+		want bool
+	}{
+		{"itself", &priv.PublicKey, true},
+		{"reloaded", &reloaded.PublicKey, true},
+		{"same parameters", &copied.PublicKey, true},
+		{"other key", &other.PublicKey, false},
+		{"other curve", &p384.PublicKey, false},
+		{"ECDSA key", &ecdsaKey.PublicKey, false},
+		{"private key", priv, false},
+	} {
+		if got := priv.PublicKey.Equal(tt.x); got != tt.want {
+			t.Errorf("public %s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		x    crypto.PrivateKey
+		want bool
+	}{
+		{"itself", priv, true},
+		{"reloaded", reloaded, true},
+		{"same parameters", &copied, true},
+		{"other key", other, false},
+		{"other curve", &p384, false},
+		{"stale public key", &stale, false},
+		{"ECDSA key", ecdsaKey, false},
+		{"public key", &priv.PublicKey, false},
+	} {
+		if got := priv.Equal(tt.x); got != tt.want {
+			t.Errorf("private %s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrivateKeyZero(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParsePrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parsed key does not share memory with its input, so the DER
+	// can be wiped right away.
+	for i := range der {
+		der[i] = 0
+	}
+
+	if !parsed.Equal(priv) {
+		t.Fatal("wiping the DER changed the parsed key")
+	}
+
+	words := parsed.D.Bits()
+	parsed.Zero()
+
+	for i, w := range words {
+		if w != 0 {
+			t.Errorf("word %d not wiped", i)
+		}
+	}
+
+	if parsed.D.Sign() != 0 {
+		t.Errorf("D = %v after Zero", parsed.D)
+	}
+
+	if _, err := SignASN1(rand.Reader, parsed, hash[:]); err == nil {
+		t.Error("zeroed key signed")
+	}
+
+	if _, err := SignDeterministic(parsed, hash[:], sha256.New); err == nil {
+		t.Error("zeroed key signed deterministically")
+	}
+
+	// Other copies of the key are not affected.
+	sig, err := SignASN1(rand.Reader, priv, hash[:])
+	if err != nil || !VerifyASN1(&priv.PublicKey, hash[:], sig) {
+		t.Errorf("original key: %v", err)
+	}
+
+	for name, key := range map[string]*PrivateKey{
+		"nil":          nil,
+		"no scalar":    {PublicKey: priv.PublicKey},
+		"zeroed again": parsed,
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: panic: %v", name, r)
+				}
+			}()
+
+			key.Zero()
+		}()
+	}
+}
+
+func TestSignContext(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	// With the same random stream, SignContext gives the same signature
+	// as PrivateKey.Sign.
+	stream := make([]byte, 1024)
+	if _, err := rand.Read(stream); err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := SignContext(context.Background(), bytes.NewReader(stream), priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := priv.Sign(bytes.NewReader(stream), hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(sig, want) {
+		t.Error("SignContext differs from PrivateKey.Sign")
+	}
+
+	if !VerifyASN1(&priv.PublicKey, hash[:], sig) {
+		t.Error("signature does not verify")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"cancelled", cancelled, context.Canceled},
+		{"deadline exceeded", expired, context.DeadlineExceeded},
+	} {
+		curve := &countCurve{Curve: priv.Curve}
+		counted := &PrivateKey{PublicKey: PublicKey{Curve: curve, X: priv.X, Y: priv.Y}, D: priv.D}
+
+		sig, err := SignContext(tt.ctx, rand.Reader, counted, hash[:])
+		if !errors.Is(err, tt.want) || sig != nil {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+
+		if n := curve.calls.Load(); n != 0 {
+			t.Errorf("%s: %d scalar multiplications", tt.name, n)
+		}
+	}
+}
+
+func TestSignVerifyASN1(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	msg := []byte("message")
+	hash := sha256.Sum256(msg)
+
+	sig, err := SignASN1(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SignASN1 and VerifyASN1 take the hash value that Sign and Verify
+	// compute from the message.
+	if !VerifyASN1(pub, hash[:], sig) || !Verify(pub, sha256.New, msg, sig) {
+		t.Error("SignASN1 signature rejected")
+	}
+
+	signed, err := Sign(rand.Reader, priv, sha256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !VerifyASN1(pub, hash[:], signed) {
+		t.Error("Sign signature rejected by VerifyASN1")
+	}
+
+	// An ECDSA key with the same scalar has the public point dG instead
+	// of d⁻¹G, and the signatures do not verify across the schemes.
+	ecdsaKey := &ecdsa.PrivateKey{D: priv.D}
+	ecdsaKey.Curve = priv.Curve
+	ecdsaKey.X, ecdsaKey.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asECGDSA := &PublicKey{Curve: priv.Curve, X: ecdsaKey.X, Y: ecdsaKey.Y}
+
+	for _, tt := range []struct {
+		name string
+		ok   bool
+	}{
+		{"ECDSA signature, EC-GDSA key", VerifyASN1(pub, hash[:], ecdsaSig)},
+		{"ECDSA signature, ECDSA point", VerifyASN1(asECGDSA, hash[:], ecdsaSig)},
+		{"EC-GDSA signature, crypto/ecdsa", ecdsa.VerifyASN1(&ecdsaKey.PublicKey, hash[:], sig)},
+		{"EC-GDSA signature, crypto/ecdsa with the EC-GDSA point", ecdsa.VerifyASN1(&ecdsa.PublicKey{Curve: priv.Curve, X: priv.X, Y: priv.Y}, hash[:], sig)},
+	} {
+		if tt.ok {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+
+	if !ecdsa.VerifyASN1(&ecdsaKey.PublicKey, hash[:], ecdsaSig) {
+		t.Error("ECDSA signature rejected by crypto/ecdsa")
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	if VerifyASN1(pub, other[:], sig) {
+		t.Error("other hash accepted")
+	}
+
+	if _, err := SignASN1(rand.Reader, &PrivateKey{}, hash[:]); err == nil {
+		t.Error("empty key signed")
+	}
+}
@@ -0,0 +1,125 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// HexString returns the uncompressed SEC 1 encoding of pub as lowercase
+// hex with a 0x prefix.
+func (pub *PublicKey) HexString() string {
+	return "0x" + hex.EncodeToString(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+}
+
+// PublicKeyFromHex parses a hex encoded SEC 1 point as returned by
+// HexString. The 0x prefix is optional and upper case digits are accepted.
+func PublicKeyFromHex(curve elliptic.Curve, s string) (*PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("ecgdsa: invalid hex public key: " + err.Error())
+	}
+
+	return NewPublicKey(curve, data)
+}
+
+// MarshalPublicKeyXY returns X || Y, each left-padded to the byte length of
+// the field, which is the uncompressed SEC 1 encoding without its 0x04
+// prefix.
+func MarshalPublicKeyXY(pub *PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	return elliptic.Marshal(pub.Curve, pub.X, pub.Y)[1:], nil
+}
+
+// ParsePublicKeyXY parses the prefix-less X || Y encoding returned by
+// MarshalPublicKeyXY. xy must be exactly twice the byte length of the
+// field and the point must be on curve.
+func ParsePublicKeyXY(curve elliptic.Curve, xy []byte) (*PublicKey, error) {
+	if len(xy) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	byteLen := (curve.Params().BitSize + 7) / 8
+	if len(xy) != 2*byteLen {
+		return nil, errors.New("ecgdsa: invalid X || Y public key length")
+	}
+
+	x := new(big.Int).SetBytes(xy[:byteLen])
+	y := new(big.Int).SetBytes(xy[byteLen:])
+
+	p := curve.Params().P
+	if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 || !curve.IsOnCurve(x, y) {
+		return nil, errors.New("ecgdsa: invalid X || Y public key point")
+	}
+
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// MarshalPublicKeyPointLE returns the uncompressed SEC 1 point of pub with
+// both coordinates in little-endian order:
+//
+//	0x04 || X (little-endian) || Y (little-endian)
+//
+// Each coordinate is padded with high zero bytes, at the end, to the byte
+// length of the field. Only the coordinate byte order differs from SEC 1.
+func MarshalPublicKeyPointLE(pub *PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	byteLen := (len(point) - 1) / 2
+
+	reverseBytes(point[1 : 1+byteLen])
+	reverseBytes(point[1+byteLen:])
+
+	return point, nil
+}
+
+// ParsePublicKeyPointLE parses a point encoded by MarshalPublicKeyPointLE.
+// The length must match the curve and the point must be on it.
+func ParsePublicKeyPointLE(curve elliptic.Curve, data []byte) (*PublicKey, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	byteLen := (curve.Params().BitSize + 7) / 8
+	if len(data) != 1+2*byteLen || data[0] != 4 {
+		return nil, errors.New("ecgdsa: invalid little-endian public key point")
+	}
+
+	point := append([]byte(nil), data...)
+	reverseBytes(point[1 : 1+byteLen])
+	reverseBytes(point[1+byteLen:])
+
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, errors.New("ecgdsa: invalid little-endian public key point")
+	}
+
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,275 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/secp256k1"
+)
+
+func TestPublicKeyHexString(t *testing.T) {
+	for _, curve := range []elliptic.Curve{
+		elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521(),
+		brainpool.P256r1(), secp256k1.S256(),
+	} {
+		name := curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := priv.PublicKey.HexString()
+
+		size := BitsToBytes(curve.Params().BitSize)
+		if !strings.HasPrefix(s, "0x04") || len(s) != 2+2*(1+2*size) || strings.ToLower(s) != s {
+			t.Errorf("%s: got %s", name, s)
+		}
+
+		for _, in := range []string{s, s[2:], "0X" + strings.ToUpper(s[2:]), " " + s + "\n"} {
+			pub, err := PublicKeyFromHex(curve, in)
+			if err != nil {
+				t.Errorf("%s: %q: %v", name, in, err)
+				continue
+			}
+
+			if !pub.Equal(&priv.PublicKey) {
+				t.Errorf("%s: %q: round trip changed the key", name, in)
+			}
+		}
+	}
+}
+
+func TestPublicKeyFromHexInvalid(t *testing.T) {
+	priv := testKey(t)
+	s := priv.PublicKey.HexString()
+
+	point := elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)
+	point[len(point)-1] ^= 1
+	offCurve := hex.EncodeToString(point)
+
+	for name, in := range map[string]string{
+		"empty":      "",
+		"prefix":     "0x",
+		"odd length": s[:len(s)-1],
+		"not hex":    s[:len(s)-2] + "zz",
+		"off curve":  offCurve,
+		"truncated":  s[:len(s)-2],
+		"wrong tag":  "0x05" + s[4:],
+	} {
+		if _, err := PublicKeyFromHex(elliptic.P256(), in); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, err := PublicKeyFromHex(elliptic.P384(), s); err == nil {
+		t.Error("P-256 point accepted on P-384")
+	}
+}
+
+func TestPublicKeyXY(t *testing.T) {
+	for _, c := range registeredCurves() {
+		curve := c.namedCurve
+
+		// The binary curves use the same layout; keys are only generated
+		// on the prime field curves here.
+		if !isPrimeField(curve) {
+			continue
+		}
+
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		xy, err := MarshalPublicKeyXY(&priv.PublicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		point := elliptic.Marshal(curve, priv.X, priv.Y)
+		if string(xy) != string(point[1:]) {
+			t.Errorf("%s: X || Y is not the SEC 1 point without its prefix", c.oid)
+		}
+
+		pub, err := ParsePublicKeyXY(curve, xy)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		if !pub.Equal(&priv.PublicKey) {
+			t.Errorf("%s: round trip changed the key", c.oid)
+		}
+
+		offCurve := append([]byte{}, xy...)
+		offCurve[len(offCurve)-1] ^= 1
+
+		for name, in := range map[string][]byte{
+			"empty":     nil,
+			"truncated": xy[:len(xy)-1],
+			"trailing":  append(append([]byte{}, xy...), 0),
+			"prefixed":  point,
+			"off curve": offCurve,
+		} {
+			if _, err := ParsePublicKeyXY(curve, in); err == nil {
+				t.Errorf("%s: %s: accepted", c.oid, name)
+			}
+		}
+	}
+
+	priv := testKey(t)
+
+	xy, err := MarshalPublicKeyXY(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParsePublicKeyXY(nil, xy); err == nil {
+		t.Error("nil curve accepted")
+	}
+
+	if _, err := MarshalPublicKeyXY(&PublicKey{}); err == nil {
+		t.Error("empty key marshalled")
+	}
+}
+
+func TestPublicKeyPointLE(t *testing.T) {
+	for _, c := range registeredCurves() {
+		curve := c.namedCurve
+
+		// As for X || Y, keys are only generated on the prime field
+		// curves.
+		if !isPrimeField(curve) {
+			continue
+		}
+
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		be := elliptic.Marshal(curve, priv.X, priv.Y)
+
+		le, err := MarshalPublicKeyPointLE(&priv.PublicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		// Only the order of the bytes within each coordinate differs.
+		size := (len(be) - 1) / 2
+		if len(le) != len(be) || le[0] != 4 {
+			t.Fatalf("%s: got %d bytes with prefix %#x", c.oid, len(le), le[0])
+		}
+
+		for i := 0; i < size; i++ {
+			if le[1+i] != be[size-i] || le[1+size+i] != be[2*size-i] {
+				t.Fatalf("%s: byte %d is not reversed", c.oid, i)
+			}
+		}
+
+		saved := append([]byte{}, le...)
+
+		fromLE, err := ParsePublicKeyPointLE(curve, le)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		if string(le) != string(saved) {
+			t.Errorf("%s: input modified", c.oid)
+		}
+
+		fromBE, err := NewPublicKey(curve, be)
+		if err != nil {
+			t.Fatalf("%s: %v", c.oid, err)
+		}
+
+		if !fromLE.Equal(&priv.PublicKey) || !fromBE.Equal(&priv.PublicKey) {
+			t.Errorf("%s: round trip changed the key", c.oid)
+		}
+
+		wrongTag := append([]byte{}, le...)
+		wrongTag[0] = 2
+
+		for name, in := range map[string][]byte{
+			"empty":      nil,
+			"big-endian": be,
+			"truncated":  le[:len(le)-1],
+			"trailing":   append(append([]byte{}, le...), 0),
+			"wrong tag":  wrongTag,
+		} {
+			if _, err := ParsePublicKeyPointLE(curve, in); err == nil {
+				t.Errorf("%s: %s: accepted", c.oid, name)
+			}
+		}
+	}
+
+	if _, err := ParsePublicKeyPointLE(nil, []byte{4}); err == nil {
+		t.Error("nil curve accepted")
+	}
+
+	if _, err := MarshalPublicKeyPointLE(&PublicKey{}); err == nil {
+		t.Error("empty key marshalled")
+	}
+}
+
+func TestNewPublicKey(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521(), secp256k1.S256()} {
+		name := curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub := &priv.PublicKey
+		size := BitsToBytes(curve.Params().BitSize)
+
+		uncompressed := pub.Bytes()
+		if len(uncompressed) != 1+2*size || uncompressed[0] != 4 {
+			t.Errorf("%s: Bytes returned %d bytes with prefix %#x", name, len(uncompressed), uncompressed[0])
+		}
+
+		compressed := elliptic.MarshalCompressed(curve, pub.X, pub.Y)
+
+		for _, in := range [][]byte{uncompressed, compressed} {
+			got, err := NewPublicKey(curve, in)
+			if err != nil {
+				t.Errorf("%s: %d bytes: %v", name, len(in), err)
+				continue
+			}
+
+			if !got.Equal(pub) || string(got.Bytes()) != string(uncompressed) {
+				t.Errorf("%s: %d bytes: round trip changed the key", name, len(in))
+			}
+		}
+
+		offCurve := append([]byte{}, uncompressed...)
+		offCurve[len(offCurve)-1] ^= 1
+
+		for _, tt := range []struct {
+			name string
+			in   []byte
+		}{
+			{"empty", nil},
+			{"prefix only", []byte{4}},
+			{"X || Y", uncompressed[1:]},
+			{"truncated", uncompressed[:len(uncompressed)-1]},
+			{"trailing", append(append([]byte{}, uncompressed...), 0)},
+			{"compressed truncated", compressed[:len(compressed)-1]},
+			{"off curve", offCurve},
+		} {
+			if _, err := NewPublicKey(curve, tt.in); !errors.Is(err, ErrInvalidPublicKey) {
+				t.Errorf("%s: %s: got %v, want ErrInvalidPublicKey", name, tt.name, err)
+			}
+		}
+	}
+
+	if _, err := NewPublicKey(nil, []byte{4}); err == nil {
+		t.Error("nil curve accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+var ErrNotFIPSApproved = errors.New("ecgdsa: not approved in FIPS mode")
+
+// fipsMode is set by SetFIPSMode.
+var fipsMode atomic.Bool
+
+// fipsHashes are the hashes allowed in FIPS mode.
+var fipsHashes = []crypto.Hash{
+	crypto.SHA224,
+	crypto.SHA256,
+	crypto.SHA384,
+	crypto.SHA512,
+	crypto.SHA512_224,
+	crypto.SHA512_256,
+}
+
+// SetFIPSMode turns the FIPS-restricted mode on or off. In FIPS mode only
+// P-256, P-384 and P-521 may be used, with a SHA-2 hash whose collision
+// resistance matches the curve: SHA-256 or longer for P-256, SHA-384 or
+// longer for P-384 and SHA-512 for P-521. Anything else fails with an
+// error wrapping ErrNotFIPSApproved, or does not verify:
+//
+//   - GenerateKey on another curve fails;
+//   - signing with a key on another curve fails, and so does Sign,
+//     SignToRS or a SigningStream with another hash;
+//   - verification on another curve, or Verify, VerifyWithRS or a
+//     VerifyingStream with another hash, reports the signature as invalid;
+//   - marshaling a key on another curve fails.
+//
+// The default is off. It is safe to call concurrently, but is meant to be
+// set once at startup.
+func SetFIPSMode(on bool) {
+	fipsMode.Store(on)
+}
+
+// FIPSMode reports whether the FIPS-restricted mode is on.
+func FIPSMode() bool {
+	return fipsMode.Load()
+}
+
+// fipsCheckCurve returns an error in FIPS mode if curve is not approved.
+func fipsCheckCurve(curve elliptic.Curve) error {
+	if !fipsMode.Load() {
+		return nil
+	}
+
+	if curve != nil && (sameCurve(curve, elliptic.P256()) ||
+		sameCurve(curve, elliptic.P384()) || sameCurve(curve, elliptic.P521())) {
+		return nil
+	}
+
+	name := "unknown"
+	if curve != nil {
+		name = curve.Params().Name
+	}
+
+	return fmt.Errorf("%w: curve %s", ErrNotFIPSApproved, name)
+}
+
+// fipsCheckHash returns an error in FIPS mode if h is not an approved hash
+// strong enough for curve. h is recognized by the type and size of the
+// hash.Hash it returns.
+func fipsCheckHash(h Hasher, curve elliptic.Curve) error {
+	if !fipsMode.Load() {
+		return nil
+	}
+
+	if err := fipsCheckCurve(curve); err != nil {
+		return err
+	}
+
+	d := h()
+
+	for _, approved := range fipsHashes {
+		if !approved.Available() {
+			continue
+		}
+
+		a := approved.New()
+		if reflect.TypeOf(a) != reflect.TypeOf(d) || a.Size() != d.Size() {
+			continue
+		}
+
+		if d.Size()*8/2 < SecurityLevel(curve) {
+			return fmt.Errorf("%w: %v is too short for %s", ErrNotFIPSApproved, approved, curve.Params().Name)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: hash", ErrNotFIPSApproved)
+}
@@ -0,0 +1,114 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestFIPSMode(t *testing.T) {
+	t.Cleanup(func() { SetFIPSMode(false) })
+
+	msg := []byte("message")
+
+	keys := make(map[string]*PrivateKey)
+	for name, curve := range map[string]elliptic.Curve{
+		"P-256":           elliptic.P256(),
+		"P-384":           elliptic.P384(),
+		"P-521":           elliptic.P521(),
+		"brainpoolP256r1": brainpool.P256r1(),
+	} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys[name] = priv
+	}
+
+	// Signatures made before FIPS mode is turned on.
+	brainpoolSig, err := Sign(rand.Reader, keys["brainpoolP256r1"], sha256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sha1Sig, err := Sign(rand.Reader, keys["P-256"], sha1.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if FIPSMode() {
+		t.Fatal("FIPS mode is on by default")
+	}
+
+	SetFIPSMode(true)
+
+	if !FIPSMode() {
+		t.Fatal("FIPS mode not turned on")
+	}
+
+	for _, tt := range []struct {
+		key  string
+		hash Hasher
+		name string
+		ok   bool
+	}{
+		{"P-256", sha256.New, "SHA-256", true},
+		{"P-256", sha512.New384, "SHA-384", true},
+		{"P-256", sha1.New, "SHA-1", false},
+		{"P-384", sha512.New384, "SHA-384", true},
+		{"P-384", sha256.New, "SHA-256", false},
+		{"P-521", sha512.New, "SHA-512", true},
+		{"P-521", sha512.New384, "SHA-384", false},
+		{"brainpoolP256r1", sha256.New, "SHA-256", false},
+	} {
+		priv := keys[tt.key]
+
+		sig, err := Sign(rand.Reader, priv, tt.hash, msg)
+		if tt.ok != (err == nil) || (err != nil && !errors.Is(err, ErrNotFIPSApproved)) {
+			t.Errorf("%s with %s: got %v", tt.key, tt.name, err)
+		}
+
+		if tt.ok && !Verify(&priv.PublicKey, tt.hash, msg, sig) {
+			t.Errorf("%s with %s: signature rejected", tt.key, tt.name)
+		}
+	}
+
+	if _, err := GenerateKey(rand.Reader, brainpool.P256r1()); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("GenerateKey on brainpoolP256r1: got %v", err)
+	}
+
+	if _, err := GenerateKey(rand.Reader, toyCurve); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("GenerateKey on a custom curve: got %v", err)
+	}
+
+	if _, err := MarshalPublicKey(&keys["brainpoolP256r1"].PublicKey); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("MarshalPublicKey on brainpoolP256r1: got %v", err)
+	}
+
+	if _, err := MarshalPublicKey(&keys["P-384"].PublicKey); err != nil {
+		t.Errorf("MarshalPublicKey on P-384: %v", err)
+	}
+
+	if Verify(&keys["brainpoolP256r1"].PublicKey, sha256.New, msg, brainpoolSig) {
+		t.Error("brainpoolP256r1 signature accepted")
+	}
+
+	if Verify(&keys["P-256"].PublicKey, sha1.New, msg, sha1Sig) {
+		t.Error("SHA-1 signature accepted")
+	}
+
+	// Turning the mode off permits everything again.
+	SetFIPSMode(false)
+
+	if !Verify(&keys["brainpoolP256r1"].PublicKey, sha256.New, msg, brainpoolSig) ||
+		!Verify(&keys["P-256"].PublicKey, sha1.New, msg, sha1Sig) {
+		t.Error("signatures rejected after FIPS mode was turned off")
+	}
+}
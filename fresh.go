@@ -0,0 +1,113 @@
+package ecgdsa
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrSignatureExpired = errors.New("ecgdsa: signature is older than the allowed age")
+	ErrSignatureFuture  = errors.New("ecgdsa: signature timestamp is in the future")
+)
+
+// timestampedSignature carries the signing time next to the signature.
+type timestampedSignature struct {
+	Timestamp int64
+	Signature []byte
+}
+
+// timestampedData frames the signed data as the 8 byte big-endian Unix
+// time in seconds followed by the message.
+func timestampedData(ts int64, message []byte) []byte {
+	data := make([]byte, 8+len(message))
+	binary.BigEndian.PutUint64(data, uint64(ts))
+	copy(data[8:], message)
+
+	return data
+}
+
+// SignWithTimestamp signs message bound to the time t. The result is the
+// ASN.1 SEQUENCE { timestamp INTEGER, signature OCTET STRING }, where
+// timestamp is t in Unix seconds and signature is the ASN.1 signature over
+// the 8 byte big-endian timestamp followed by message.
+func SignWithTimestamp(rand io.Reader, priv *PrivateKey, h Hasher, message []byte, t time.Time) ([]byte, error) {
+	ts := t.Unix()
+
+	sig, err := Sign(rand, priv, h, timestampedData(ts, message))
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(timestampedSignature{
+		Timestamp: ts,
+		Signature: sig,
+	})
+}
+
+// DefaultClockSkew is the clock difference between signer and verifier
+// that VerifyFresh tolerates.
+const DefaultClockSkew = time.Minute
+
+// FreshOpts selects the checks of VerifyFreshOpts.
+type FreshOpts struct {
+	// MaxAge is the largest accepted age of the signature.
+	MaxAge time.Duration
+
+	// ClockSkew is how far the clock of the signer may be ahead of or
+	// behind CurrentTime. A timestamp up to ClockSkew in the future is
+	// accepted, and so is one up to MaxAge + ClockSkew in the past. Zero
+	// means the clocks agree exactly.
+	ClockSkew time.Duration
+
+	// CurrentTime is the time the age is measured against. The zero value
+	// means time.Now().
+	CurrentTime time.Time
+}
+
+// VerifyFresh verifies a signature made by SignWithTimestamp and rejects it
+// with ErrSignatureExpired if it was made more than maxAge ago, or with
+// ErrSignatureFuture if its timestamp lies ahead of the local clock. Both
+// checks allow DefaultClockSkew.
+func VerifyFresh(pub *PublicKey, h Hasher, message, sig []byte, maxAge time.Duration) (bool, error) {
+	return VerifyFreshOpts(pub, h, message, sig, &FreshOpts{MaxAge: maxAge, ClockSkew: DefaultClockSkew})
+}
+
+// VerifyFreshOpts is like VerifyFresh with the age limit, clock skew and
+// current time taken from opts.
+func VerifyFreshOpts(pub *PublicKey, h Hasher, message, sig []byte, opts *FreshOpts) (bool, error) {
+	if opts == nil {
+		return false, errors.New("ecgdsa: missing freshness options")
+	}
+
+	var ts timestampedSignature
+
+	rest, err := asn1.Unmarshal(sig, &ts)
+	if err != nil {
+		return false, err
+	} else if len(rest) != 0 {
+		return false, errors.New("ecgdsa: trailing data after timestamped signature")
+	}
+
+	if !Verify(pub, h, timestampedData(ts.Timestamp, message), ts.Signature) {
+		return false, nil
+	}
+
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	age := now.Sub(time.Unix(ts.Timestamp, 0))
+	if age < -opts.ClockSkew {
+		return false, ErrSignatureFuture
+	}
+
+	if age > opts.MaxAge+opts.ClockSkew {
+		return false, ErrSignatureExpired
+	}
+
+	return true, nil
+}
@@ -0,0 +1,77 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyFreshOpts(t *testing.T) {
+	priv := testKey(t)
+	signed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	message := []byte("message")
+
+	sig, err := SignWithTimestamp(rand.Reader, priv, sha256.New, message, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const maxAge = 10 * time.Minute
+
+	for _, tt := range []struct {
+		name    string
+		now     time.Time
+		skew    time.Duration
+		message string
+		ok      bool
+		err     error
+	}{
+		{"fresh", signed.Add(time.Minute), 0, "message", true, nil},
+		{"at max age", signed.Add(maxAge), 0, "message", true, nil},
+		{"expired", signed.Add(maxAge + time.Second), 0, "message", false, ErrSignatureExpired},
+		{"expired within skew", signed.Add(maxAge + time.Second), time.Minute, "message", true, nil},
+		{"expired beyond skew", signed.Add(maxAge + time.Minute + time.Second), time.Minute, "message", false, ErrSignatureExpired},
+		{"future", signed.Add(-time.Second), 0, "message", false, ErrSignatureFuture},
+		{"future within skew", signed.Add(-30 * time.Second), time.Minute, "message", true, nil},
+		{"future beyond skew", signed.Add(-time.Minute - time.Second), time.Minute, "message", false, ErrSignatureFuture},
+		{"other message", signed.Add(time.Minute), 0, "other", false, nil},
+		{"other message expired", signed.Add(maxAge + time.Hour), 0, "other", false, nil},
+	} {
+		ok, err := VerifyFreshOpts(&priv.PublicKey, sha256.New, []byte(tt.message), sig, &FreshOpts{
+			MaxAge:      maxAge,
+			ClockSkew:   tt.skew,
+			CurrentTime: tt.now,
+		})
+		if ok != tt.ok || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, ok, err, tt.ok, tt.err)
+		}
+	}
+}
+
+func TestVerifyFresh(t *testing.T) {
+	priv := testKey(t)
+	message := []byte("message")
+
+	for _, tt := range []struct {
+		name   string
+		signed time.Time
+		ok     bool
+		err    error
+	}{
+		{"fresh", time.Now(), true, nil},
+		{"skewed", time.Now().Add(DefaultClockSkew / 2), true, nil},
+		{"expired", time.Now().Add(-time.Hour), false, ErrSignatureExpired},
+	} {
+		sig, err := SignWithTimestamp(rand.Reader, priv, sha256.New, message, tt.signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ok, err := VerifyFresh(&priv.PublicKey, sha256.New, message, sig, time.Minute)
+		if ok != tt.ok || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, ok, err, tt.ok, tt.err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"fmt"
+	"sort"
+)
+
+type hashInfo struct {
+	hash crypto.Hash
+	oid  asn1.ObjectIdentifier
+}
+
+var signatureHashes = make([]hashInfo, 0)
+
+func init() {
+	RegisterHash(crypto.RIPEMD160, asn1.ObjectIdentifier{1, 3, 36, 3, 2, 1})
+	RegisterHash(crypto.SHA1, asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26})
+
+	RegisterHash(crypto.SHA224, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4})
+	RegisterHash(crypto.SHA256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1})
+	RegisterHash(crypto.SHA384, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2})
+	RegisterHash(crypto.SHA512, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3})
+	RegisterHash(crypto.SHA512_224, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 5})
+	RegisterHash(crypto.SHA512_256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 6})
+
+	RegisterHash(crypto.SHA3_224, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 7})
+	RegisterHash(crypto.SHA3_256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 8})
+	RegisterHash(crypto.SHA3_384, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 9})
+	RegisterHash(crypto.SHA3_512, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 10})
+}
+
+// RegisterHash makes h selectable for signing under its algorithm
+// identifier oid. Registering a hash again replaces its OID. An OID that
+// already belongs to another hash is not added; the conflict is recorded
+// and reported by VerifyRegistry.
+//
+// The implementation of h must itself be linked in with crypto.RegisterHash,
+// usually by importing its package, before it is usable.
+func RegisterHash(h crypto.Hash, oid asn1.ObjectIdentifier) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := range signatureHashes {
+		cur := &signatureHashes[i]
+
+		if cur.oid.Equal(oid) && cur.hash != h {
+			registryErrors = append(registryErrors, fmt.Errorf("ecgdsa: hash OID %s is already registered", oid))
+			return
+		}
+	}
+
+	for i := range signatureHashes {
+		cur := &signatureHashes[i]
+
+		if cur.hash == h {
+			cur.oid = oid
+			return
+		}
+	}
+
+	signatureHashes = append(signatureHashes, hashInfo{
+		hash: h,
+		oid:  oid,
+	})
+}
+
+// SupportedHashes returns the registered hashes whose implementation is
+// available, sorted by their crypto.Hash value.
+func SupportedHashes() []crypto.Hash {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	hashes := make([]crypto.Hash, 0, len(signatureHashes))
+	for i := range signatureHashes {
+		if h := signatureHashes[i].hash; h.Available() {
+			hashes = append(hashes, h)
+		}
+	}
+
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	return hashes
+}
+
+func hashFromOid(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for i := range signatureHashes {
+		cur := &signatureHashes[i]
+		if cur.oid.Equal(oid) {
+			return cur.hash, true
+		}
+	}
+
+	return 0, false
+}
+
+// VerifyWithHashOID verifies the ASN.1 encoded signature sig of msg, hashing
+// msg with the hash registered under hashOID with RegisterHash, so the hash
+// can be chosen by an algorithm identifier sent next to the signature. An
+// unregistered OID or a hash whose implementation is not linked in is an
+// error; an invalid signature is not.
+func VerifyWithHashOID(pub *PublicKey, hashOID asn1.ObjectIdentifier, msg, sig []byte) (bool, error) {
+	h, ok := hashFromOid(hashOID)
+	if !ok {
+		return false, fmt.Errorf("ecgdsa: unknown hash algorithm %s", hashOID)
+	}
+
+	if !h.Available() {
+		return false, ErrHashUnavailable
+	}
+
+	return Verify(pub, h.New, msg, sig), nil
+}
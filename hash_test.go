@@ -0,0 +1,132 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestSupportedHashes(t *testing.T) {
+	saved := append([]hashInfo{}, signatureHashes...)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		signatureHashes = saved
+		registryMu.Unlock()
+	})
+
+	contains := func(hashes []crypto.Hash, h crypto.Hash) bool {
+		for _, cur := range hashes {
+			if cur == h {
+				return true
+			}
+		}
+		return false
+	}
+
+	before := SupportedHashes()
+	for _, h := range []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		if !contains(before, h) {
+			t.Errorf("%v missing from %v", h, before)
+		}
+	}
+
+	if contains(before, crypto.MD5) {
+		t.Fatal("MD5 listed before it was registered")
+	}
+
+	md5OID := asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 5}
+	RegisterHash(crypto.MD5, md5OID)
+
+	// Registering a hash again, with the same or another OID, does not
+	// list it twice.
+	RegisterHash(crypto.MD5, md5OID)
+	RegisterHash(crypto.SHA256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1})
+
+	// A hash whose implementation is not linked in is not listed.
+	if !crypto.MD4.Available() {
+		RegisterHash(crypto.MD4, asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 4})
+	}
+
+	after := SupportedHashes()
+	if !sort.SliceIsSorted(after, func(i, j int) bool { return after[i] < after[j] }) {
+		t.Errorf("hashes %v not sorted", after)
+	}
+
+	if !contains(after, crypto.MD5) || len(after) != len(before)+1 {
+		t.Errorf("got %v after registering MD5 to %v", after, before)
+	}
+
+	// The registered hash is usable through its OID.
+	priv := testKey(t)
+	msg := []byte("message")
+
+	sig, err := Sign(rand.Reader, priv, md5.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := VerifyWithHashOID(&priv.PublicKey, md5OID, msg, sig); err != nil || !ok {
+		t.Errorf("VerifyWithHashOID = %v, %v", ok, err)
+	}
+}
+
+func TestVerifyWithHashOID(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	msg := []byte("message")
+
+	tests := []struct {
+		hash crypto.Hash
+		oid  asn1.ObjectIdentifier
+	}{
+		{crypto.SHA1, asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}},
+		{crypto.SHA224, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4}},
+		{crypto.SHA256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}},
+		{crypto.SHA384, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}},
+		{crypto.SHA512, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}},
+	}
+
+	for i, tt := range tests {
+		sig, err := Sign(rand.Reader, priv, tt.hash.New, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok, err := VerifyWithHashOID(pub, tt.oid, msg, sig); !ok || err != nil {
+			t.Errorf("%v: got %v, %v", tt.hash, ok, err)
+		}
+
+		if ok, err := VerifyWithHashOID(pub, tt.oid, []byte("other"), sig); ok || err != nil {
+			t.Errorf("%v: other message: got %v, %v", tt.hash, ok, err)
+		}
+
+		// The signature does not verify under another hash; that is an
+		// invalid signature, not an error.
+		other := tests[(i+1)%len(tests)]
+		if ok, err := VerifyWithHashOID(pub, other.oid, msg, sig); ok || err != nil {
+			t.Errorf("%v as %v: got %v, %v", tt.hash, other.hash, ok, err)
+		}
+	}
+
+	sig, err := Sign(rand.Reader, priv, crypto.SHA256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyWithHashOID(pub, asn1.ObjectIdentifier{1, 2, 3, 4}, msg, sig); err == nil {
+		t.Error("unknown OID accepted")
+	}
+
+	// RIPEMD-160 is registered but only usable once its implementation is
+	// linked in.
+	if !crypto.RIPEMD160.Available() {
+		_, err := VerifyWithHashOID(pub, asn1.ObjectIdentifier{1, 3, 36, 3, 2, 1}, msg, sig)
+		if !errors.Is(err, ErrHashUnavailable) {
+			t.Errorf("RIPEMD-160: got %v, want ErrHashUnavailable", err)
+		}
+	}
+}
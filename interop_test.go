@@ -0,0 +1,282 @@
+//go:build interop
+
+package ecgdsa
+
+// The tests in this file check the encodings of this package against the
+// openssl command and run only with go test -tags interop. They skip when
+// openssl is not on PATH. OpenSSL releases without EC-GDSA can still parse
+// the ASN.1 and decrypt PBES2, so only the checks that load an EC-GDSA key
+// into OpenSSL are skipped there.
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// openssl runs the openssl command with args and returns its output, or
+// an error with its diagnostics.
+func openssl(t *testing.T, args ...string) ([]byte, error) {
+	t.Helper()
+
+	path, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl not found on PATH")
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New(err.Error() + ": " + stderr.String())
+	}
+
+	return out, nil
+}
+
+// mustOpenSSL is openssl that fails the test on error.
+func mustOpenSSL(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	out, err := openssl(t, args...)
+	if err != nil {
+		t.Fatalf("openssl %s: %v", strings.Join(args, " "), err)
+	}
+
+	return out
+}
+
+// opensslHasECGDSA reports whether openssl can load the PEM key in file.
+func opensslHasECGDSA(t *testing.T, file string) bool {
+	t.Helper()
+
+	_, err := openssl(t, "pkey", "-in", file, "-noout")
+
+	return err == nil
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// opensslDER converts the PEM file to DER with openssl asn1parse, which
+// also checks that the encoding is valid ASN.1.
+func opensslDER(t *testing.T, file string) []byte {
+	t.Helper()
+
+	out := file + ".der"
+	mustOpenSSL(t, "asn1parse", "-in", file, "-out", out, "-noout")
+
+	der, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return der
+}
+
+func TestInteropPrivateKeyPEM(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemBytes, err := EncodePrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeTemp(t, "key.pem", pemBytes)
+
+	if !bytes.Equal(opensslDER(t, file), der) {
+		t.Error("openssl asn1parse read a different private key")
+	}
+
+	text := string(mustOpenSSL(t, "asn1parse", "-in", file))
+	if !strings.Contains(text, ":1.3.36.3.3.2.5.2.1") || !strings.Contains(text, ":prime256v1") {
+		t.Errorf("missing EC-GDSA or curve OID:\n%s", text)
+	}
+
+	if !opensslHasECGDSA(t, file) {
+		t.Skip("openssl has no EC-GDSA")
+	}
+
+	got, err := ParsePrivateKey(pem2der(t, mustOpenSSL(t, "pkey", "-in", file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(priv) {
+		t.Error("key written by openssl pkey differs")
+	}
+}
+
+func TestInteropPublicKeyPEM(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemBytes, err := EncodePublicKeyPEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeTemp(t, "pub.pem", pemBytes)
+
+	if !bytes.Equal(opensslDER(t, file), der) {
+		t.Error("openssl asn1parse read a different public key")
+	}
+
+	keyPEM, err := EncodePrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !opensslHasECGDSA(t, writeTemp(t, "key.pem", keyPEM)) {
+		t.Skip("openssl has no EC-GDSA")
+	}
+
+	got, err := ParsePublicKey(pem2der(t, mustOpenSSL(t, "pkey", "-pubin", "-in", file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(&priv.PublicKey) {
+		t.Error("key written by openssl pkey differs")
+	}
+}
+
+// TestInteropEncryptedPrivateKeyDecrypt decrypts MarshalPrivateKeyWithPassword
+// output with openssl kdf and openssl enc, which need no EC-GDSA support.
+func TestInteropEncryptedPrivateKeyDecrypt(t *testing.T) {
+	priv := testKey(t)
+
+	want, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := MarshalPrivateKeyWithPassword(priv, []byte("secret"), &EncryptOptions{Iterations: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		t.Fatal(err)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		t.Fatal(err)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		t.Fatal(err)
+	}
+
+	key := mustOpenSSL(t, "kdf", "-binary", "-keylen", "32",
+		"-kdfopt", "digest:SHA256",
+		"-kdfopt", "pass:secret",
+		"-kdfopt", "hexsalt:"+hex.EncodeToString(kdf.Salt),
+		"-kdfopt", "iter:"+strconv.Itoa(kdf.IterationCount),
+		"PBKDF2")
+
+	ciphertext := writeTemp(t, "key.enc", info.EncryptedData)
+
+	plain := mustOpenSSL(t, "enc", "-d", "-aes-256-cbc", "-in", ciphertext,
+		"-K", hex.EncodeToString(key), "-iv", hex.EncodeToString(iv))
+
+	if !bytes.Equal(plain, want) {
+		t.Error("openssl decrypted a different private key")
+	}
+}
+
+// TestInteropEncryptedPrivateKeyOpenSSL parses keys encrypted by
+// openssl pkcs8 -topk8 -v2. Without EC-GDSA in OpenSSL it encrypts an
+// OpenSSL ECDSA key instead and checks the decryption only.
+func TestInteropEncryptedPrivateKeyOpenSSL(t *testing.T) {
+	priv := testKey(t)
+
+	pemBytes, err := EncodePrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeTemp(t, "key.pem", pemBytes)
+	ecgdsa := opensslHasECGDSA(t, file)
+
+	if !ecgdsa {
+		file = writeTemp(t, "ecdsa.pem", mustOpenSSL(t, "genpkey",
+			"-algorithm", "EC", "-pkeyopt", "ec_paramgen_curve:P-256"))
+	}
+
+	plain := pem2der(t, mustOpenSSL(t, "pkcs8", "-topk8", "-nocrypt", "-in", file))
+
+	for _, cipher := range []string{"aes-128-cbc", "aes-192-cbc", "aes-256-cbc"} {
+		der := pem2der(t, mustOpenSSL(t, "pkcs8", "-topk8", "-v2", cipher,
+			"-iter", "2048", "-passout", "pass:secret", "-in", file))
+
+		got, err := decryptPKCS8(der, []byte("secret"))
+		if err != nil {
+			t.Fatalf("%s: %v", cipher, err)
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("%s: decrypted key differs", cipher)
+		}
+
+		if !ecgdsa {
+			continue
+		}
+
+		key, err := ParsePrivateKeyWithPassword(der, []byte("secret"))
+		if err != nil {
+			t.Fatalf("%s: %v", cipher, err)
+		}
+
+		if !key.Equal(priv) {
+			t.Errorf("%s: parsed key differs", cipher)
+		}
+	}
+}
+
+func pem2der(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("openssl wrote no PEM block")
+	}
+
+	return block.Bytes
+}
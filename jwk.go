@@ -0,0 +1,202 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/secp256k1"
+)
+
+// jwkCurves maps JWK "crv" names to curves. The first name listed for a
+// curve is the one written; the brainpoolPxxxr1 spellings are accepted
+// when parsing.
+var jwkCurves = []struct {
+	name  string
+	curve elliptic.Curve
+}{
+	{"P-256", elliptic.P256()},
+	{"P-384", elliptic.P384()},
+	{"P-521", elliptic.P521()},
+	{"BP-256", brainpool.P256r1()},
+	{"BP-384", brainpool.P384r1()},
+	{"BP-512", brainpool.P512r1()},
+	{"secp256k1", secp256k1.S256()},
+
+	{"brainpoolP256r1", brainpool.P256r1()},
+	{"brainpoolP384r1", brainpool.P384r1()},
+	{"brainpoolP512r1", brainpool.P512r1()},
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+}
+
+func jwkCurveName(curve elliptic.Curve) (string, bool) {
+	for _, c := range jwkCurves {
+		if c.curve == curve {
+			return c.name, true
+		}
+	}
+
+	return "", false
+}
+
+func jwkCurve(name string) (elliptic.Curve, bool) {
+	for _, c := range jwkCurves {
+		if c.name == name {
+			return c.curve, true
+		}
+	}
+
+	return nil, false
+}
+
+// MarshalJWK returns pub as an RFC 7517 JSON Web Key:
+//
+//	{"kty":"EC","crv":"P-256","x":"...","y":"..."}
+//
+// x and y are unpadded base64url, each left-padded with zeros to the byte
+// length of the field. Only P-256, P-384, P-521, brainpoolP256r1,
+// brainpoolP384r1, brainpoolP512r1 (as BP-256, BP-384 and BP-512) and
+// secp256k1 have a JWK name.
+func MarshalJWK(pub *PublicKey) ([]byte, error) {
+	key, err := newJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(key)
+}
+
+// MarshalPrivateJWK is like MarshalJWK and adds the private scalar as "d",
+// left-padded to the byte length of the curve order.
+func MarshalPrivateJWK(priv *PrivateKey) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	key, err := newJWK(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	n := priv.Curve.Params().N
+	if priv.D.Sign() <= 0 || priv.D.Cmp(n) >= 0 {
+		return nil, ErrInvalidPrivateKeyValue
+	}
+
+	key.D = base64.RawURLEncoding.EncodeToString(priv.D.FillBytes(make([]byte, BitsToBytes(n.BitLen()))))
+
+	return json.Marshal(key)
+}
+
+func newJWK(pub *PublicKey) (*jwk, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	name, ok := jwkCurveName(pub.Curve)
+	if !ok {
+		return nil, ErrUnsupportedCurve
+	}
+
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	byteLen := (len(point) - 1) / 2
+
+	return &jwk{
+		Kty: "EC",
+		Crv: name,
+		X:   base64.RawURLEncoding.EncodeToString(point[1 : 1+byteLen]),
+		Y:   base64.RawURLEncoding.EncodeToString(point[1+byteLen:]),
+	}, nil
+}
+
+// ParseJWK parses an EC JSON Web Key. It returns a *PrivateKey if the key
+// has a "d" member and a *PublicKey otherwise. The coordinates must have
+// exactly the byte length of the field and d that of the order; the point
+// must be on the curve and, for a private key, be the public point of d.
+func ParseJWK(data []byte) (crypto.PublicKey, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	var key jwk
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+
+	if key.Kty != "EC" {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, key.Kty)
+	}
+
+	curve, ok := jwkCurve(key.Crv)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedCurve, key.Crv)
+	}
+
+	byteLen := (curve.Params().BitSize + 7) / 8
+
+	x, err := decodeJWKInt(key.X, byteLen)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeJWKInt(key.Y, byteLen)
+	if err != nil {
+		return nil, err
+	}
+
+	p := curve.Params().P
+	if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 || !curve.IsOnCurve(x, y) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	pub := PublicKey{Curve: curve, X: x, Y: y}
+	if key.D == "" {
+		return &pub, nil
+	}
+
+	n := curve.Params().N
+
+	d, err := decodeJWKInt(key.D, BitsToBytes(n.BitLen()))
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, ErrInvalidPrivateKeyValue
+	}
+
+	if px, py := XY(d, curve); px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+		return nil, ErrKeyMismatch
+	}
+
+	return &PrivateKey{PublicKey: pub, D: d}, nil
+}
+
+func decodeJWKInt(s string, size int) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("ecgdsa: invalid JWK member: " + err.Error())
+	}
+
+	if len(b) != size {
+		return nil, fmt.Errorf("ecgdsa: JWK member is %d bytes, want %d", len(b), size)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,193 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func jwkMembers(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		crv   string
+		curve elliptic.Curve
+	}{
+		{"P-256", elliptic.P256()},
+		{"P-384", elliptic.P384()},
+		{"P-521", elliptic.P521()},
+		{"BP-256", brainpool.P256r1()},
+	} {
+		priv, err := GenerateKey(rand.Reader, tt.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pubJWK, err := MarshalJWK(&priv.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		privJWK, err := MarshalPrivateJWK(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := jwkMembers(t, pubJWK)
+		if m["kty"] != "EC" || m["crv"] != tt.crv || m["d"] != "" {
+			t.Errorf("%s: got %s", tt.crv, pubJWK)
+		}
+
+		if m := jwkMembers(t, privJWK); m["d"] == "" {
+			t.Errorf("%s: private JWK has no d", tt.crv)
+		}
+
+		pub, err := ParseJWK(pubJWK)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pub, ok := pub.(*PublicKey); !ok || !pub.Equal(&priv.PublicKey) {
+			t.Errorf("%s: public round trip gave %v", tt.crv, pub)
+		}
+
+		got, err := ParseJWK(privJWK)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, ok := got.(*PrivateKey); !ok || !got.Equal(priv) {
+			t.Errorf("%s: private round trip gave %T", tt.crv, got)
+		}
+	}
+}
+
+// TestJWKPadding checks that coordinates and d are left-padded to the
+// fixed length of the curve, with a P-521 key, whose leading byte is
+// always 0 or 1, and a P-256 key with a short x.
+func TestJWKPadding(t *testing.T) {
+	p521, err := GenerateKey(rand.Reader, elliptic.P521())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p256 *PrivateKey
+	for p256 == nil || p256.X.BitLen() > 248 {
+		if p256, err = GenerateKey(rand.Reader, elliptic.P256()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		priv *PrivateKey
+		size int
+	}{
+		{p521, 66},
+		{p256, 32},
+	} {
+		data, err := MarshalPrivateJWK(tt.priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, value := range jwkMembers(t, data) {
+			if name == "kty" || name == "crv" {
+				continue
+			}
+
+			b, err := base64.RawURLEncoding.DecodeString(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(b) != tt.size {
+				t.Errorf("%s: %s is %d bytes, want %d", tt.priv.Params().Name, name, len(b), tt.size)
+			}
+		}
+	}
+
+	// An unpadded coordinate is rejected.
+	m := jwkMembers(t, mustMarshalJWK(t, &p256.PublicKey))
+	m["x"] = base64.RawURLEncoding.EncodeToString(p256.X.Bytes())
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseJWK(data); err == nil {
+		t.Error("unpadded x accepted")
+	}
+}
+
+func mustMarshalJWK(t *testing.T, pub *PublicKey) []byte {
+	t.Helper()
+
+	data, err := MarshalJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestParseJWKInvalid(t *testing.T) {
+	priv := testKey(t)
+	other := testKey(t)
+
+	privJWK, err := MarshalPrivateJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherJWK, err := MarshalPrivateJWK(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func(member, value string) []byte {
+		m := jwkMembers(t, privJWK)
+		m[member] = value
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return data
+	}
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"unknown crv", edit("crv", "P-192"), ErrUnsupportedCurve},
+		{"wrong kty", edit("kty", "OKP"), ErrUnknownAlgorithm},
+		{"d of another key", edit("d", jwkMembers(t, otherJWK)["d"]), ErrKeyMismatch},
+		{"zero d", edit("d", base64.RawURLEncoding.EncodeToString(make([]byte, 32))), ErrInvalidPrivateKeyValue},
+		{"off curve", edit("y", jwkMembers(t, otherJWK)["y"]), ErrInvalidPublicKey},
+	} {
+		if _, err := ParseJWK(tt.data); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	if _, err := MarshalJWK(&PublicKey{Curve: &countCurve{Curve: elliptic.P256()}, X: priv.X, Y: priv.Y}); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("curve without a JWK name: got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package ecgdsa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+)
+
+var ErrKeyFileMAC = errors.New("ecgdsa: key file integrity check failed")
+
+var oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+
+// integrityProtectedKey is the key file written by SignKeyFile:
+//
+//	IntegrityProtectedKey ::= SEQUENCE {
+//	  macAlgorithm AlgorithmIdentifier, -- hmacWithSHA256
+//	  privateKey   OCTET STRING,        -- PKCS#8 DER
+//	  mac          OCTET STRING         -- HMAC over privateKey
+//	}
+type integrityProtectedKey struct {
+	MACAlgorithm pkix.AlgorithmIdentifier
+	PrivateKey   []byte
+	MAC          []byte
+}
+
+func keyFileMAC(secret, der []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write(der)
+
+	return m.Sum(nil)
+}
+
+// SignKeyFile marshals key as PKCS#8 and wraps it with an HMAC-SHA256
+// keyed by secret, so that changes to the stored file are detected by
+// VerifyKeyFileIntegrity. The key itself is stored in the clear: this
+// gives integrity, not confidentiality.
+func SignKeyFile(key *PrivateKey, secret []byte) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("ecgdsa: empty key file secret")
+	}
+
+	der, err := MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(integrityProtectedKey{
+		MACAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+		PrivateKey: der,
+		MAC:        keyFileMAC(secret, der),
+	})
+}
+
+// VerifyKeyFileIntegrity checks the HMAC of a key file written by
+// SignKeyFile and returns the key. A wrong MAC, whether from tampering or
+// a wrong secret, gives ErrKeyFileMAC; a file that cannot be decoded gives
+// a different error.
+func VerifyKeyFileIntegrity(data, secret []byte) (*PrivateKey, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("ecgdsa: empty key file secret")
+	}
+
+	var file integrityProtectedKey
+
+	rest, err := asn1.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.New("ecgdsa: invalid key file: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("ecgdsa: trailing data after key file")
+	}
+
+	if !file.MACAlgorithm.Algorithm.Equal(oidHMACWithSHA256) {
+		return nil, errors.New("ecgdsa: unknown key file MAC algorithm")
+	}
+
+	if !hmac.Equal(file.MAC, keyFileMAC(secret, file.PrivateKey)) {
+		return nil, ErrKeyFileMAC
+	}
+
+	return ParsePrivateKey(file.PrivateKey)
+}
@@ -0,0 +1,102 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"testing"
+)
+
+func TestKeyFileIntegrity(t *testing.T) {
+	priv := testKey(t)
+	secret := []byte("key file secret")
+
+	file, err := SignKeyFile(priv, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := VerifyKeyFileIntegrity(file, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(priv) {
+		t.Error("key changed")
+	}
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := bytes.Index(file, der)
+	if start < 0 {
+		t.Fatal("key file does not hold the PKCS#8 key")
+	}
+
+	// Every flipped byte of the stored key or of the MAC is detected as
+	// a MAC mismatch.
+	mac := keyFileMAC(secret, der)
+	macStart := len(file) - len(mac)
+
+	for i := start; i < len(file); i++ {
+		if i >= start+len(der) && i < macStart {
+			continue
+		}
+
+		tampered := append([]byte{}, file...)
+		tampered[i] ^= 1
+
+		if _, err := VerifyKeyFileIntegrity(tampered, secret); !errors.Is(err, ErrKeyFileMAC) {
+			t.Fatalf("byte %d flipped: got %v, want ErrKeyFileMAC", i, err)
+		}
+	}
+
+	if _, err := VerifyKeyFileIntegrity(file, []byte("other secret")); !errors.Is(err, ErrKeyFileMAC) {
+		t.Errorf("wrong secret: got %v, want ErrKeyFileMAC", err)
+	}
+
+	// A correct MAC over bytes that are not a key is a parse failure.
+	garbage := []byte("not a key")
+	notAKey, err := asn1.Marshal(integrityProtectedKey{
+		MACAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+		PrivateKey:   garbage,
+		MAC:          keyFileMAC(secret, garbage),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherAlgorithm, err := asn1.Marshal(integrityProtectedKey{
+		MACAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}, Parameters: asn1.NullRawValue},
+		PrivateKey:   der,
+		MAC:          mac,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		data   []byte
+		secret []byte
+	}{
+		{"empty", nil, secret},
+		{"truncated", file[:len(file)-1], secret},
+		{"trailing data", append(append([]byte{}, file...), 0), secret},
+		{"not a key", notAKey, secret},
+		{"other MAC algorithm", otherAlgorithm, secret},
+		{"empty secret", file, nil},
+	} {
+		_, err := VerifyKeyFileIntegrity(tt.data, tt.secret)
+		if err == nil || errors.Is(err, ErrKeyFileMAC) {
+			t.Errorf("%s: got %v, want an error other than ErrKeyFileMAC", tt.name, err)
+		}
+	}
+
+	if _, err := SignKeyFile(priv, nil); err == nil {
+		t.Error("empty secret accepted")
+	}
+}
@@ -0,0 +1,105 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+)
+
+// KeyIDMethod selects how a subject key identifier is derived from a
+// public key.
+type KeyIDMethod int
+
+const (
+	// KeyIDRFC5280 is the SHA-1 hash of the subjectPublicKey BIT STRING
+	// value, as described in RFC 5280, section 4.2.1.2, method (1).
+	KeyIDRFC5280 KeyIDMethod = iota
+
+	// KeyIDRFC7093Method1 is the leftmost 160 bits of the SHA-256 hash of
+	// the subjectPublicKey BIT STRING value (RFC 7093, section 2, method 1).
+	KeyIDRFC7093Method1
+
+	// KeyIDRFC7093Method2 is the leftmost 160 bits of the SHA-384 hash of
+	// the subjectPublicKey BIT STRING value (RFC 7093, section 2, method 2).
+	KeyIDRFC7093Method2
+
+	// KeyIDRFC7093Method3 is the leftmost 160 bits of the SHA-512 hash of
+	// the subjectPublicKey BIT STRING value (RFC 7093, section 2, method 3).
+	KeyIDRFC7093Method3
+)
+
+// SubjectKeyID returns the key identifier of pub computed with RFC 7093
+// method 1.
+func SubjectKeyID(pub *PublicKey) []byte {
+	id, _ := SubjectKeyIDWithMethod(pub, KeyIDRFC7093Method1)
+	return id
+}
+
+// SubjectKeyIDWithMethod returns the key identifier of pub computed with
+// the given method. Both RFC methods hash the subjectPublicKey BIT STRING
+// value only, that is the SEC 1 encoded point, excluding its tag, length
+// and unused-bits octet.
+func SubjectKeyIDWithMethod(pub *PublicKey, method KeyIDMethod) ([]byte, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("ecgdsa: invalid public key")
+	}
+
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+
+	switch method {
+	case KeyIDRFC5280:
+		sum := sha1.Sum(point)
+		return sum[:], nil
+	case KeyIDRFC7093Method1:
+		sum := sha256.Sum256(point)
+		return sum[:20], nil
+	case KeyIDRFC7093Method2:
+		sum := sha512.Sum384(point)
+		return sum[:20], nil
+	case KeyIDRFC7093Method3:
+		sum := sha512.Sum512(point)
+		return sum[:20], nil
+	}
+
+	return nil, errors.New("ecgdsa: unknown key identifier method")
+}
+
+// Fingerprint returns the lowercase hex SHA-256 of the DER encoded
+// SubjectPublicKeyInfo of pub, the same value as
+//
+//	openssl pkey -pubin -outform DER | sha256sum
+//
+// It returns an empty string if pub cannot be marshaled.
+func (pub *PublicKey) Fingerprint() string {
+	der, err := MarshalPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(der)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// String returns the curve name and the first 16 hex digits of the
+// fingerprint of pub, for logs.
+func (pub *PublicKey) String() string {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return "ECGDSA <invalid>"
+	}
+
+	name := pub.Curve.Params().Name
+	if name == "" {
+		name = "custom"
+	}
+
+	fp := pub.Fingerprint()
+	if len(fp) > 16 {
+		fp = fp[:16]
+	}
+
+	return "ECGDSA " + name + " " + fp
+}
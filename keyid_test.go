@@ -0,0 +1,123 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// TestSubjectKeyID uses the EC-GDSA public key d⁻¹G on P-256 of the RFC
+// 6979, A.2.5 private key d. The identifiers were computed independently
+// from the uncompressed point.
+func TestSubjectKeyID(t *testing.T) {
+	d, _ := new(big.Int).SetString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721", 16)
+	wantX, _ := new(big.Int).SetString("285e92b5b49b9b0d59e3a4a257d12ef5e9fe0d0e08c21032c82999abcc1a97e7", 16)
+
+	priv, err := NewPrivateKeyFromScalar(elliptic.P256(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if priv.X.Cmp(wantX) != 0 {
+		t.Fatal("wrong public key")
+	}
+
+	pub := &priv.PublicKey
+
+	for _, tt := range []struct {
+		method KeyIDMethod
+		want   string
+	}{
+		{KeyIDRFC5280, "81f77a95f2ef0ca94a9bfe1cb9670534addfb0af"},
+		{KeyIDRFC7093Method1, "4ad21b3e6afbfc09524b6f8eafc29a87c7916b96"},
+		{KeyIDRFC7093Method2, "7d2f1c204940e15ea875cc6798fb50e7282ec7f5"},
+		{KeyIDRFC7093Method3, "903d5b9dbce32dc484bd4af756c1ecbea49291fe"},
+	} {
+		got, err := SubjectKeyIDWithMethod(pub, tt.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("method %d: got %x, want %s", tt.method, got, tt.want)
+		}
+	}
+
+	method1, _ := SubjectKeyIDWithMethod(pub, KeyIDRFC7093Method1)
+	if !bytes.Equal(SubjectKeyID(pub), method1) {
+		t.Error("SubjectKeyID does not use RFC 7093 method 1")
+	}
+
+	if _, err := SubjectKeyIDWithMethod(pub, KeyIDRFC7093Method3+1); err == nil {
+		t.Error("unknown method accepted")
+	}
+}
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	pub := &TestKey(elliptic.P256(), 0).PublicKey
+
+	// SHA-256 of the SubjectPublicKeyInfo of standard test key 0 on
+	// P-256, computed independently.
+	const want = "e66f9d41573f3200777491caa51d5891d9a9751dda63a3c144a4d702910e920c"
+
+	if got := pub.Fingerprint(); got != want {
+		t.Errorf("fingerprint %s, want %s", got, want)
+	}
+
+	der, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParsePublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Fingerprint() != want {
+		t.Error("parsed key has another fingerprint")
+	}
+
+	if got := pub.String(); got != "ECGDSA P-256 "+want[:16] {
+		t.Errorf("String() = %q", got)
+	}
+
+	if other := testKey(t).PublicKey; other.Fingerprint() == want {
+		t.Error("another key has the same fingerprint")
+	}
+
+	params := *elliptic.P256().Params()
+	params.Name = ""
+
+	for _, tt := range []struct {
+		name string
+		pub  *PublicKey
+		fp   string
+		str  string
+	}{
+		{"nil", nil, "", "ECGDSA <invalid>"},
+		{"empty", &PublicKey{}, "", "ECGDSA <invalid>"},
+	} {
+		if got := tt.pub.Fingerprint(); got != tt.fp {
+			t.Errorf("%s: fingerprint %q, want %q", tt.name, got, tt.fp)
+		}
+
+		if got := tt.pub.String(); got != tt.str {
+			t.Errorf("%s: String() = %q, want %q", tt.name, got, tt.str)
+		}
+	}
+
+	// A key on an unregistered, unnamed curve has no fingerprint.
+	custom := &PublicKey{Curve: &params, X: pub.X, Y: pub.Y}
+
+	if got := custom.Fingerprint(); got != "" {
+		t.Errorf("unregistered curve: fingerprint %q", got)
+	}
+
+	if got := custom.String(); !strings.HasPrefix(got, "ECGDSA custom") {
+		t.Errorf("unregistered curve: String() = %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+)
+
+// CombineKeyParts rebuilds a private key from a scalar and a public key
+// that are stored apart. scalarDER is an EC private key structure (RFC
+// 5915) on curve, whose own public key field, if any, is ignored, and
+// pubDER is a PKIX public key. ErrKeyMismatch is returned if the public
+// point is not the one derived from the scalar.
+func CombineKeyParts(curve elliptic.Curve, scalarDER, pubDER []byte) (*PrivateKey, error) {
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	priv, err := parseECPrivateKey(curve, scalarDER, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if priv.D.Sign() == 0 {
+		return nil, errors.New("ecgdsa: invalid elliptic curve private key value")
+	}
+
+	pub, err := ParsePublicKey(pubDER)
+	if err != nil {
+		return nil, err
+	}
+
+	if pub.Curve != curve || !bigIntEqual(pub.X, priv.X) || !bigIntEqual(pub.Y, priv.Y) {
+		return nil, ErrKeyMismatch
+	}
+
+	return priv, nil
+}
@@ -0,0 +1,77 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"testing"
+)
+
+func TestCombineKeyParts(t *testing.T) {
+	priv := testKey(t)
+	other := testKey(t)
+
+	p384, err := GenerateKey(rand.Reader, elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scalar := mustMarshalSEC1(t, priv)
+
+	// The scalar with another key's public key embedded, which is ignored.
+	var withOther ecPrivateKey
+	if _, err := asn1.Unmarshal(scalar, &withOther); err != nil {
+		t.Fatal(err)
+	}
+
+	var otherSEC1 ecPrivateKey
+	if _, err := asn1.Unmarshal(mustMarshalSEC1(t, other), &otherSEC1); err != nil {
+		t.Fatal(err)
+	}
+	withOther.PublicKey = otherSEC1.PublicKey
+
+	scalarWithOther, err := asn1.Marshal(withOther)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubDER := func(pub *PublicKey) []byte {
+		der, err := MarshalPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return der
+	}
+
+	for _, tt := range []struct {
+		name   string
+		scalar []byte
+		pub    []byte
+		err    error
+	}{
+		{"matching", scalar, pubDER(&priv.PublicKey), nil},
+		{"embedded public key ignored", scalarWithOther, pubDER(&priv.PublicKey), nil},
+		{"mismatched", scalar, pubDER(&other.PublicKey), ErrKeyMismatch},
+		{"other curve", scalar, pubDER(&p384.PublicKey), ErrKeyMismatch},
+	} {
+		got, err := CombineKeyParts(elliptic.P256(), tt.scalar, tt.pub)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+
+		if err == nil && !got.Equal(priv) {
+			t.Errorf("%s: got another key", tt.name)
+		}
+	}
+
+	if _, err := CombineKeyParts(nil, scalar, pubDER(&priv.PublicKey)); err == nil {
+		t.Error("nil curve accepted")
+	}
+
+	if _, err := CombineKeyParts(elliptic.P256(), scalar, []byte{0x30, 0x00}); err == nil {
+		t.Error("malformed public key accepted")
+	}
+}
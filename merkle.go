@@ -0,0 +1,168 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"errors"
+	"io"
+)
+
+// MerkleProof proves that a leaf is part of a Merkle tree.
+type MerkleProof struct {
+	// Index is the position of the leaf in the tree.
+	Index int
+
+	// Siblings are the hashes of the sibling nodes from the leaf level up
+	// to, but not including, the root.
+	Siblings [][]byte
+}
+
+// The tree is built as follows. Leaves are hashed as H(0x00 || leaf) and
+// inner nodes as H(0x01 || left || right), so that a leaf can never be
+// passed off as an inner node. When a level has an odd number of nodes the
+// last node is paired with itself. Note that this makes the trees of
+// [a, b, c] and [a, b, c, c] share a root; callers that care must commit
+// to the number of leaves separately.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+func merkleLeaf(h crypto.Hash, leaf []byte) []byte {
+	d := h.New()
+	d.Write([]byte{merkleLeafPrefix})
+	d.Write(leaf)
+
+	return d.Sum(nil)
+}
+
+func merkleNode(h crypto.Hash, left, right []byte) []byte {
+	d := h.New()
+	d.Write([]byte{merkleNodePrefix})
+	d.Write(left)
+	d.Write(right)
+
+	return d.Sum(nil)
+}
+
+// merkleLevels returns every level of the tree, leaves first.
+func merkleLevels(leaves [][]byte, h crypto.Hash) [][][]byte {
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleLeaf(h, leaf)
+	}
+
+	levels := [][][]byte{level}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+
+			next = append(next, merkleNode(h, level[i], right))
+		}
+
+		level = next
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+// BuildMerkleRoot returns the root of the Merkle tree over leaves, or nil
+// if there are no leaves or h is not available.
+func BuildMerkleRoot(leaves [][]byte, h crypto.Hash) []byte {
+	if len(leaves) == 0 || !h.Available() {
+		return nil
+	}
+
+	levels := merkleLevels(leaves, h)
+
+	return levels[len(levels)-1][0]
+}
+
+// BuildMerkleProof returns the inclusion proof for leaves[index].
+func BuildMerkleProof(leaves [][]byte, index int, h crypto.Hash) (*MerkleProof, error) {
+	if !h.Available() {
+		return nil, ErrHashUnavailable
+	}
+
+	if index < 0 || index >= len(leaves) {
+		return nil, errors.New("ecgdsa: Merkle leaf index out of range")
+	}
+
+	levels := merkleLevels(leaves, h)
+
+	proof := &MerkleProof{Index: index}
+
+	i := index
+	for _, level := range levels[:len(levels)-1] {
+		sibling := i ^ 1
+		if sibling >= len(level) {
+			sibling = i
+		}
+
+		proof.Siblings = append(proof.Siblings, level[sibling])
+		i /= 2
+	}
+
+	return proof, nil
+}
+
+// merkleRootFromProof recomputes the root from a leaf and its proof.
+func merkleRootFromProof(leaf []byte, proof *MerkleProof, h crypto.Hash) []byte {
+	node := merkleLeaf(h, leaf)
+
+	i := proof.Index
+	for _, sibling := range proof.Siblings {
+		if i%2 == 0 {
+			node = merkleNode(h, node, sibling)
+		} else {
+			node = merkleNode(h, sibling, node)
+		}
+
+		i /= 2
+	}
+
+	return node
+}
+
+// SignMerkleRoot builds the Merkle tree over leaves and signs its root. It
+// returns the root and the ASN.1 encoded signature over it.
+func SignMerkleRoot(rand io.Reader, priv *PrivateKey, leaves [][]byte, h crypto.Hash) (root, sig []byte, err error) {
+	if !h.Available() {
+		return nil, nil, ErrHashUnavailable
+	}
+
+	if len(leaves) == 0 {
+		return nil, nil, errors.New("ecgdsa: no Merkle leaves to sign")
+	}
+
+	root = BuildMerkleRoot(leaves, h)
+
+	sig, err = Sign(rand, priv, h.New, root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, sig, nil
+}
+
+// VerifyMerkleInclusion reports whether leaf is included, according to
+// proof, in a tree whose root was signed with SignMerkleRoot.
+func VerifyMerkleInclusion(pub *PublicKey, leaf []byte, proof *MerkleProof, sig []byte, h crypto.Hash) bool {
+	if proof == nil || proof.Index < 0 || !h.Available() {
+		return false
+	}
+
+	if proof.Index>>len(proof.Siblings) != 0 {
+		return false
+	}
+
+	root := merkleRootFromProof(leaf, proof, h)
+
+	return Verify(pub, h.New, root, sig)
+}
@@ -0,0 +1,98 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+func merkleTestLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = []byte(fmt.Sprint("leaf ", i))
+	}
+
+	return leaves
+}
+
+func TestMerkleRootConstruction(t *testing.T) {
+	h := crypto.SHA256
+	leaves := merkleTestLeaves(3)
+
+	l0, l1, l2 := merkleLeaf(h, leaves[0]), merkleLeaf(h, leaves[1]), merkleLeaf(h, leaves[2])
+
+	// The odd last node is paired with itself.
+	want := merkleNode(h, merkleNode(h, l0, l1), merkleNode(h, l2, l2))
+
+	if got := BuildMerkleRoot(leaves, h); !bytes.Equal(got, want) {
+		t.Errorf("root %x, want %x", got, want)
+	}
+
+	if got := BuildMerkleRoot(merkleTestLeaves(1), h); !bytes.Equal(got, merkleLeaf(h, []byte("leaf 0"))) {
+		t.Error("root of one leaf is not the leaf hash")
+	}
+
+	if BuildMerkleRoot(nil, h) != nil {
+		t.Error("root of no leaves is not nil")
+	}
+}
+
+func TestVerifyMerkleInclusion(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	h := crypto.SHA256
+
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		leaves := merkleTestLeaves(n)
+
+		root, sig, err := SignMerkleRoot(rand.Reader, priv, leaves, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(root, BuildMerkleRoot(leaves, h)) {
+			t.Fatalf("%d leaves: SignMerkleRoot returned another root", n)
+		}
+
+		for i, leaf := range leaves {
+			proof, err := BuildMerkleProof(leaves, i, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !VerifyMerkleInclusion(pub, leaf, proof, sig, h) {
+				t.Errorf("%d leaves, leaf %d: valid proof rejected", n, i)
+			}
+
+			if VerifyMerkleInclusion(pub, []byte("other"), proof, sig, h) {
+				t.Errorf("%d leaves, leaf %d: other leaf accepted", n, i)
+			}
+
+			for j := range proof.Siblings {
+				tampered := &MerkleProof{Index: proof.Index, Siblings: append([][]byte{}, proof.Siblings...)}
+				tampered.Siblings[j] = append([]byte{}, proof.Siblings[j]...)
+				tampered.Siblings[j][0] ^= 1
+
+				if VerifyMerkleInclusion(pub, leaf, tampered, sig, h) {
+					t.Errorf("%d leaves, leaf %d: tampered sibling %d accepted", n, i, j)
+				}
+			}
+
+			for _, index := range []int{-1, 1 << len(proof.Siblings), i + 1<<len(proof.Siblings)} {
+				moved := &MerkleProof{Index: index, Siblings: proof.Siblings}
+
+				if VerifyMerkleInclusion(pub, leaf, moved, sig, h) {
+					t.Errorf("%d leaves, leaf %d: index %d accepted", n, i, index)
+				}
+			}
+		}
+
+		for _, index := range []int{-1, n} {
+			if _, err := BuildMerkleProof(leaves, index, h); err == nil {
+				t.Errorf("%d leaves: proof for index %d built", n, index)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/pedroalbanese/brainpool"
+	"github.com/pedroalbanese/frp256v1"
+	"github.com/pedroalbanese/go-nums"
+	"github.com/pedroalbanese/secp256k1"
+	"github.com/pedroalbanese/tom"
+)
+
+// Curve identifiers of the mini public key encoding.
+//
+//	0x01 P-224             0x10 brainpoolP160r1   0x11 brainpoolP160t1
+//	0x02 P-256             0x12 brainpoolP192r1   0x13 brainpoolP192t1
+//	0x03 P-384             0x14 brainpoolP224r1   0x15 brainpoolP224t1
+//	0x04 P-521             0x16 brainpoolP256r1   0x17 brainpoolP256t1
+//	                       0x18 brainpoolP320r1   0x19 brainpoolP320t1
+//	0x20 secp256k1         0x1a brainpoolP384r1   0x1b brainpoolP384t1
+//	0x21 FRP256v1          0x1c brainpoolP512r1   0x1d brainpoolP512t1
+//	0x30 numsp256d1
+//	0x31 numsp384d1        0x40 tom256
+//	0x32 numsp512d1        0x41 tom384
+//
+// The binary curves and the twisted Edwards NUMS curves have no id, since
+// their points cannot be decompressed as y² = x³ + ax + b over a prime field.
+var miniCurves = []struct {
+	id    byte
+	curve elliptic.Curve
+}{
+	{0x01, elliptic.P224()},
+	{0x02, elliptic.P256()},
+	{0x03, elliptic.P384()},
+	{0x04, elliptic.P521()},
+
+	{0x10, brainpool.P160r1()},
+	{0x11, brainpool.P160t1()},
+	{0x12, brainpool.P192r1()},
+	{0x13, brainpool.P192t1()},
+	{0x14, brainpool.P224r1()},
+	{0x15, brainpool.P224t1()},
+	{0x16, brainpool.P256r1()},
+	{0x17, brainpool.P256t1()},
+	{0x18, brainpool.P320r1()},
+	{0x19, brainpool.P320t1()},
+	{0x1a, brainpool.P384r1()},
+	{0x1b, brainpool.P384t1()},
+	{0x1c, brainpool.P512r1()},
+	{0x1d, brainpool.P512t1()},
+
+	{0x20, secp256k1.S256()},
+	{0x21, frp256v1.P256()},
+
+	{0x30, nums.P256d1()},
+	{0x31, nums.P384d1()},
+	{0x32, nums.P512d1()},
+
+	{0x40, tom.P256()},
+	{0x41, tom.P384()},
+}
+
+// MarshalPublicKeyMini encodes pub as a curve id byte followed by the
+// compressed point, which is 34 bytes for a 256-bit curve. It is the
+// smallest self-describing public key encoding of the package; see
+// miniCurves for the id table.
+func MarshalPublicKeyMini(pub *PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	for _, c := range miniCurves {
+		if c.curve == pub.Curve {
+			point := elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y)
+			return append([]byte{c.id}, point...), nil
+		}
+	}
+
+	return nil, errors.New("ecgdsa: curve has no mini encoding id")
+}
+
+// ParsePublicKeyMini decodes a public key encoded by MarshalPublicKeyMini.
+func ParsePublicKeyMini(data []byte) (*PublicKey, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	if len(data) < 2 {
+		return nil, errors.New("ecgdsa: mini public key too short")
+	}
+
+	for _, c := range miniCurves {
+		if c.id != data[0] {
+			continue
+		}
+
+		x, y := unmarshalCompressed(c.curve, data[1:])
+		if x == nil {
+			return nil, errors.New("ecgdsa: invalid mini public key point")
+		}
+
+		return &PublicKey{Curve: c.curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("ecgdsa: unknown mini curve id 0x%02x", data[0])
+}
@@ -0,0 +1,88 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestPublicKeyMiniRoundTrip(t *testing.T) {
+	seen := make(map[byte]bool)
+
+	for _, c := range miniCurves {
+		if seen[c.id] {
+			t.Errorf("id 0x%02x used twice", c.id)
+		}
+		seen[c.id] = true
+
+		priv, err := GenerateKey(rand.Reader, c.curve)
+		if err != nil {
+			t.Fatalf("0x%02x: %v", c.id, err)
+		}
+
+		data, err := MarshalPublicKeyMini(&priv.PublicKey)
+		if err != nil {
+			t.Fatalf("0x%02x: %v", c.id, err)
+		}
+
+		if data[0] != c.id || len(data) != 2+BitsToBytes(c.curve.Params().BitSize) {
+			t.Errorf("0x%02x: got %x", c.id, data)
+		}
+
+		pub, err := ParsePublicKeyMini(data)
+		if err != nil {
+			t.Fatalf("0x%02x: %v", c.id, err)
+		}
+
+		if !pub.Equal(&priv.PublicKey) {
+			t.Errorf("0x%02x: round trip changed the key", c.id)
+		}
+	}
+
+	data, err := MarshalPublicKeyMini(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 34 {
+		t.Errorf("P-256 key is %d bytes, want 34", len(data))
+	}
+}
+
+func TestParsePublicKeyMiniInvalid(t *testing.T) {
+	data, err := MarshalPublicKeyMini(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []byte{0x00, 0x05, 0x0f, 0x1e, 0x22, 0x33, 0x42, 0xff} {
+		unknown := append([]byte{id}, data[1:]...)
+
+		if _, err := ParsePublicKeyMini(unknown); err == nil {
+			t.Errorf("unknown id 0x%02x accepted", id)
+		}
+	}
+
+	offCurve := append([]byte{}, data...)
+	offCurve[1] = 4
+
+	for name, b := range map[string][]byte{
+		"empty":       nil,
+		"id only":     data[:1],
+		"truncated":   data[:len(data)-1],
+		"tag 04":      offCurve,
+		"wrong curve": append([]byte{0x03}, data[1:]...),
+	} {
+		if _, err := ParsePublicKeyMini(b); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	// A curve without an id cannot be encoded.
+	curve := &countCurve{Curve: elliptic.P256()}
+	priv := testKey(t)
+
+	if _, err := MarshalPublicKeyMini(&PublicKey{Curve: curve, X: priv.X, Y: priv.Y}); err == nil {
+		t.Error("curve without an id encoded")
+	}
+}
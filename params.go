@@ -0,0 +1,273 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+var oidPrimeField = asn1.ObjectIdentifier{1, 2, 840, 10045, 1, 1}
+
+// parseCurveParameters decodes the parameters of an EC AlgorithmIdentifier,
+// which are the CHOICE
+//
+//	ECParameters ::= CHOICE {
+//	  namedCurve    OBJECT IDENTIFIER,
+//	  implicitCurve NULL,
+//	  specifiedCurve SpecifiedECDomain
+//	}
+//
+// A named curve is looked up in the registry and a specified curve is
+// matched against the registered curves by its domain parameters, so that
+// explicit encodings of the NIST, brainpool and other registered curves
+// map back to the standard curve values. A specified curve that matches
+// none of them is rejected unless opts.AllowExplicitCurve is set, in which
+// case it is built with customCurve.
+func parseCurveParameters(der []byte, opts *ParseOptions) (elliptic.Curve, error) {
+	input := cryptobyte.String(der)
+
+	switch {
+	case input.PeekASN1Tag(cbasn1.OBJECT_IDENTIFIER):
+		oid := new(asn1.ObjectIdentifier)
+		if !input.ReadASN1ObjectIdentifier(oid) || !input.Empty() {
+			return nil, errors.New("ecgdsa: invalid curve OID")
+		}
+
+		curve := NamedCurveFromOid(*oid)
+		if curve == nil {
+			return nil, fmt.Errorf("%w %s", ErrUnsupportedCurve, *oid)
+		}
+
+		return curve, nil
+
+	case input.PeekASN1Tag(cbasn1.SEQUENCE):
+		params, err := parseSpecifiedECDomain(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if curve := namedCurveFromParams(params); curve != nil {
+			return curve, nil
+		}
+
+		if opts == nil || !opts.AllowExplicitCurve {
+			return nil, fmt.Errorf("%w: specified curve parameters do not match a registered curve", ErrUnsupportedCurve)
+		}
+
+		curve, err := customCurve("", params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: specified curve parameters do not match a supported curve: %v", ErrUnsupportedCurve, err)
+		}
+
+		return curve, nil
+
+	case input.PeekASN1Tag(cbasn1.NULL):
+		return nil, fmt.Errorf("%w: implicitly specified curve parameters are not supported", ErrUnsupportedCurve)
+	}
+
+	return nil, errors.New("ecgdsa: invalid curve parameters")
+}
+
+// specifiedCurve holds the prime field domain parameters decoded from a
+// SpecifiedECDomain.
+type specifiedCurve struct {
+	P, A, B, N *big.Int
+	Base       []byte
+}
+
+// parseSpecifiedECDomain decodes
+//
+//	SpecifiedECDomain ::= SEQUENCE {
+//	  version  INTEGER,
+//	  fieldID  SEQUENCE { fieldType OBJECT IDENTIFIER, prime INTEGER },
+//	  curve    SEQUENCE { a OCTET STRING, b OCTET STRING, seed BIT STRING OPTIONAL },
+//	  base     OCTET STRING,
+//	  order    INTEGER,
+//	  cofactor INTEGER OPTIONAL,
+//	  ...
+//	}
+//
+// Only prime fields are supported.
+func parseSpecifiedECDomain(input cryptobyte.String) (*specifiedCurve, error) {
+	var (
+		domain, fieldID, curve cryptobyte.String
+		version                int64
+		fieldType              asn1.ObjectIdentifier
+		a, b, base             []byte
+	)
+
+	params := &specifiedCurve{
+		P: new(big.Int),
+		N: new(big.Int),
+	}
+
+	if !input.ReadASN1(&domain, cbasn1.SEQUENCE) || !input.Empty() ||
+		!domain.ReadASN1Integer(&version) ||
+		!domain.ReadASN1(&fieldID, cbasn1.SEQUENCE) ||
+		!fieldID.ReadASN1ObjectIdentifier(&fieldType) {
+		return nil, errors.New("ecgdsa: invalid specified curve parameters")
+	}
+
+	if !fieldType.Equal(oidPrimeField) {
+		return nil, errors.New("ecgdsa: only prime field curve parameters are supported")
+	}
+
+	if !fieldID.ReadASN1Integer(params.P) ||
+		!domain.ReadASN1(&curve, cbasn1.SEQUENCE) ||
+		!curve.ReadASN1Bytes(&a, cbasn1.OCTET_STRING) ||
+		!curve.ReadASN1Bytes(&b, cbasn1.OCTET_STRING) ||
+		!domain.ReadASN1Bytes(&base, cbasn1.OCTET_STRING) ||
+		!domain.ReadASN1Integer(params.N) {
+		return nil, errors.New("ecgdsa: invalid specified curve parameters")
+	}
+
+	params.A = new(big.Int).SetBytes(a)
+	params.B = new(big.Int).SetBytes(b)
+	params.Base = base
+
+	return params, nil
+}
+
+// namedCurveFromParams returns the registered curve with the given domain
+// parameters, or nil.
+func namedCurveFromParams(params *specifiedCurve) elliptic.Curve {
+	curves := registeredCurves()
+	for i := range curves {
+		curve := curves[i].namedCurve
+		if curveHasParams(curve, params) {
+			return curve
+		}
+	}
+
+	return nil
+}
+
+func curveHasParams(curve elliptic.Curve, params *specifiedCurve) bool {
+	cp := curve.Params()
+
+	if cp.P.Cmp(params.P) != 0 || cp.N.Cmp(params.N) != 0 || cp.B.Cmp(params.B) != 0 {
+		return false
+	}
+
+	if !bytes.Equal(params.Base, elliptic.Marshal(curve, cp.Gx, cp.Gy)) &&
+		!bytes.Equal(params.Base, elliptic.MarshalCompressed(curve, cp.Gx, cp.Gy)) {
+		return false
+	}
+
+	a := curveA(cp)
+
+	return a != nil && a.Cmp(params.A) == 0
+}
+
+// minCustomFieldBits is the smallest field customCurve accepts.
+const minCustomFieldBits = 224
+
+// customCurve builds a curve from domain parameters that match no
+// registered curve. elliptic.CurveParams only implements a = -3, so other
+// curves are rejected. The field must be prime and at least 224 bits, the
+// order must be prime and within the Hasse bound of the field size for a
+// small cofactor, and the base point must be on the curve. Keys on such a
+// curve can be used but not marshaled, since the curve has no OID; check
+// keys from untrusted sources with Validate.
+func customCurve(name string, params *specifiedCurve) (elliptic.Curve, error) {
+	p, n := params.P, params.N
+
+	if p.Sign() <= 0 || !p.ProbablyPrime(20) {
+		return nil, errors.New("field is not prime")
+	}
+
+	if p.BitLen() < minCustomFieldBits {
+		return nil, fmt.Errorf("field is smaller than %d bits", minCustomFieldBits)
+	}
+
+	if n.Sign() <= 0 || !n.ProbablyPrime(20) {
+		return nil, errors.New("order is not prime")
+	}
+
+	if !orderMatchesField(p, n) {
+		return nil, errors.New("order does not match the field size")
+	}
+
+	if params.A.Cmp(p) >= 0 || params.B.Cmp(p) >= 0 {
+		return nil, errors.New("parameter out of range")
+	}
+
+	if new(big.Int).Sub(p, params.A).Cmp(big.NewInt(3)) != 0 {
+		return nil, errors.New("curves with a != -3 have no implementation")
+	}
+
+	curve := &elliptic.CurveParams{
+		Name:    name,
+		P:       p,
+		N:       n,
+		B:       params.B,
+		BitSize: p.BitLen(),
+	}
+
+	// Unmarshal checks the point against P, B and BitSize only.
+	gx, gy := elliptic.Unmarshal(curve, params.Base)
+	if gx == nil {
+		gx, gy = elliptic.UnmarshalCompressed(curve, params.Base)
+	}
+
+	if gx == nil {
+		return nil, errors.New("base point is not on the curve")
+	}
+
+	curve.Gx, curve.Gy = gx, gy
+
+	return curve, nil
+}
+
+// curveA returns the coefficient a of the short Weierstrass equation
+// y² = x³ + ax + b, which elliptic.CurveParams does not carry. It is
+// recovered from the generator as a = (Gy² - Gx³ - b) / Gx mod p.
+func curveA(params *elliptic.CurveParams) *big.Int {
+	p := params.P
+
+	xInv := new(big.Int).ModInverse(params.Gx, p)
+	if xInv == nil {
+		return nil
+	}
+
+	y2 := new(big.Int).Mul(params.Gy, params.Gy)
+
+	x3 := new(big.Int).Mul(params.Gx, params.Gx)
+	x3.Mul(x3, params.Gx)
+
+	a := y2.Sub(y2, x3)
+	a.Sub(a, params.B)
+	a.Mul(a, xInv)
+
+	return a.Mod(a, p)
+}
+
+// orderMatchesField reports whether a curve over the field of size p can
+// have a subgroup of order n with a cofactor of at most 4: n must be
+// larger than 4·sqrt(p), so that the cofactor h is determined by n, and
+// |h·n - (p+1)| must be at most 2·sqrt(p) as the Hasse bound requires.
+func orderMatchesField(p, n *big.Int) bool {
+	sqrtP := new(big.Int).Sqrt(p)
+	sqrtP.Add(sqrtP, big.NewInt(1))
+
+	if n.Cmp(new(big.Int).Lsh(sqrtP, 2)) <= 0 {
+		return false
+	}
+
+	h := estimateCofactor(&elliptic.CurveParams{P: p, N: n})
+	if h.Sign() <= 0 || h.Cmp(big.NewInt(4)) > 0 {
+		return false
+	}
+
+	t := new(big.Int).Mul(h, n)
+	t.Sub(t, p)
+	t.Sub(t, big.NewInt(1))
+
+	return t.Abs(t).Cmp(new(big.Int).Lsh(sqrtP, 1)) <= 0
+}
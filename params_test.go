@@ -0,0 +1,244 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// marshalSpecifiedCurve encodes params, with a = p-3 and base point
+// (gx, gy), as SpecifiedECDomain.
+func marshalSpecifiedCurve(params *elliptic.CurveParams, gx, gy *big.Int) []byte {
+	size := (params.P.BitLen() + 7) / 8
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+
+	base := make([]byte, 1+2*size)
+	base[0] = 4
+	gx.FillBytes(base[1 : 1+size])
+	gy.FillBytes(base[1+size:])
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(1)
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(asn1.ObjectIdentifier{1, 2, 840, 10045, 1, 1})
+			b.AddASN1BigInt(params.P)
+		})
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1OctetString(a.FillBytes(make([]byte, size)))
+			b.AddASN1OctetString(params.B.FillBytes(make([]byte, size)))
+		})
+		b.AddASN1OctetString(base)
+		b.AddASN1BigInt(params.N)
+		b.AddASN1Int64(1)
+	})
+
+	return b.BytesOrPanic()
+}
+
+// unregisteredP256 is P-256 with 2G as base point, so that it matches no
+// registered curve.
+func unregisteredP256() []byte {
+	curve := elliptic.P256()
+	params := curve.Params()
+	gx, gy := curve.Double(params.Gx, params.Gy)
+
+	return marshalSpecifiedCurve(params, gx, gy)
+}
+
+func TestParseCurveParametersExplicit(t *testing.T) {
+	allow := &ParseOptions{AllowExplicitCurve: true}
+	p256 := elliptic.P256().Params()
+
+	// Explicit parameters of a registered curve are always accepted.
+	for _, opts := range []*ParseOptions{nil, allow} {
+		curve, err := parseCurveParameters(marshalSpecifiedCurve(p256, p256.Gx, p256.Gy), opts)
+		if err != nil || curve != elliptic.P256() {
+			t.Errorf("registered curve: got %v, %v", curve, err)
+		}
+	}
+
+	if _, err := parseCurveParameters(unregisteredP256(), nil); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("unregistered curve by default: got %v, want ErrUnsupportedCurve", err)
+	}
+
+	curve, err := parseCurveParameters(unregisteredP256(), allow)
+	if err != nil {
+		t.Fatalf("unregistered curve when allowed: %v", err)
+	}
+
+	if gx, _ := elliptic.P256().Double(p256.Gx, p256.Gy); curve.Params().Gx.Cmp(gx) != 0 {
+		t.Error("custom curve has the wrong base point")
+	}
+
+	composite := *p256
+	composite.N = new(big.Int).Add(p256.N, big.NewInt(1))
+
+	// A prime order far from the field size fails the Hasse bound.
+	mismatch := *p256
+	mismatch.N = new(big.Int).Add(p256.N, new(big.Int).Lsh(big.NewInt(1), 200))
+	for !mismatch.N.ProbablyPrime(20) {
+		mismatch.N.Add(mismatch.N, big.NewInt(1))
+	}
+
+	small := &elliptic.CurveParams{
+		P:  new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1)),
+		N:  big.NewInt(7),
+		B:  big.NewInt(1),
+		Gx: big.NewInt(1),
+		Gy: big.NewInt(1),
+	}
+
+	for _, tt := range []struct {
+		name   string
+		params *elliptic.CurveParams
+	}{
+		{"composite N", &composite},
+		{"N/P mismatch", &mismatch},
+		{"small field", small},
+	} {
+		der := marshalSpecifiedCurve(tt.params, tt.params.Gx, tt.params.Gy)
+		if _, err := parseCurveParameters(der, allow); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
+
+func TestParsePublicKeyWithOptions(t *testing.T) {
+	der, err := MarshalPublicKey(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spki pkixPublicKey
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		t.Fatal(err)
+	}
+
+	spki.Algo.Parameters = asn1.RawValue{FullBytes: unregisteredP256()}
+
+	der, err = asn1.Marshal(spki)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParsePublicKey(der); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("ParsePublicKey: got %v, want ErrUnsupportedCurve", err)
+	}
+
+	pub, err := ParsePublicKeyWithOptions(der, &ParseOptions{AllowExplicitCurve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pub.Curve == elliptic.P256() {
+		t.Error("explicit curve parsed as P-256")
+	}
+}
+
+// TestParseKeyParameterChoice checks both alternatives of the
+// ECParameters CHOICE, a named curve OID and a SpecifiedECDomain, in the
+// algorithm parameters of SPKI and PKCS#8 keys.
+func TestParseKeyParameterChoice(t *testing.T) {
+	priv := testKey(t)
+	p256 := elliptic.P256().Params()
+
+	pubDER, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spki pkixPublicKey
+	if _, err := asn1.Unmarshal(pubDER, &spki); err != nil {
+		t.Fatal(err)
+	}
+
+	var pkcs pkcs8
+	if _, err := asn1.Unmarshal(privDER, &pkcs); err != nil {
+		t.Fatal(err)
+	}
+
+	withParams := func(params []byte) (pub, priv []byte) {
+		spki := spki
+		spki.Algo.Parameters = asn1.RawValue{FullBytes: params}
+
+		pkcs := pkcs
+		pkcs.Algo.Parameters = asn1.RawValue{FullBytes: params}
+
+		pub, err := asn1.Marshal(spki)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		priv, err = asn1.Marshal(pkcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return pub, priv
+	}
+
+	oid, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		params []byte
+	}{
+		{"named curve", oid},
+		{"specified curve", marshalSpecifiedCurve(p256, p256.Gx, p256.Gy)},
+	} {
+		pubDER, privDER := withParams(tt.params)
+
+		pub, err := ParsePublicKey(pubDER)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if pub.Curve != elliptic.P256() || !pub.Equal(&priv.PublicKey) {
+			t.Errorf("%s: wrong public key", tt.name)
+		}
+
+		got, err := ParsePrivateKey(privDER)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if got.Curve != elliptic.P256() || !got.Equal(priv) {
+			t.Errorf("%s: wrong private key", tt.name)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		params []byte
+		err    error
+	}{
+		{"implicit curve", []byte{0x05, 0x00}, ErrUnsupportedCurve},
+		{"unknown OID", []byte{0x06, 0x03, 0x2a, 0x03, 0x04}, ErrUnsupportedCurve},
+		{"INTEGER", []byte{0x02, 0x01, 0x01}, nil},
+		{"OCTET STRING", []byte{0x04, 0x01, 0x00}, nil},
+	} {
+		pubDER, privDER := withParams(tt.params)
+
+		if _, err := ParsePublicKey(pubDER); err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+			t.Errorf("%s: ParsePublicKey: got %v", tt.name, err)
+		}
+
+		if _, err := ParsePrivateKey(privDER); err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+			t.Errorf("%s: ParsePrivateKey: got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package ecgdsa
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	pemPrivateKeyType = "PRIVATE KEY"
+	pemPublicKeyType  = "PUBLIC KEY"
+)
+
+const (
+	// maxPEMLine bounds a single line read from the input. No valid PEM
+	// line comes close to it: a longer line is skipped as garbage between
+	// blocks and fails decoding with ErrPEMLineTooLong inside a block.
+	maxPEMLine = 4096
+
+	// maxPEMBlock bounds the size of a single block. A block that grows
+	// beyond it is abandoned as garbage.
+	maxPEMBlock = 1 << 20
+)
+
+// ErrPEMLineTooLong is returned by DecodePEMKeys for a line longer than
+// 4096 bytes inside a PEM block.
+var ErrPEMLineTooLong = errors.New("ecgdsa: PEM line too long")
+
+var (
+	pemBegin = []byte("-----BEGIN ")
+	pemEnd   = []byte("-----END ")
+)
+
+// MarshalPrivateKeyPEM returns the PKCS#8 DER encoding of key together with
+// the same bytes wrapped in a "PRIVATE KEY" PEM block.
+func MarshalPrivateKeyPEM(key *PrivateKey) (der []byte, pemBytes []byte, err error) {
+	der, err = MarshalPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pemBytes = pem.EncodeToMemory(&pem.Block{
+		Type:  pemPrivateKeyType,
+		Bytes: der,
+	})
+
+	return der, pemBytes, nil
+}
+
+// EncodePrivateKeyPEM returns key as PKCS#8 in a "PRIVATE KEY" PEM block.
+func EncodePrivateKeyPEM(key *PrivateKey) ([]byte, error) {
+	_, pemBytes, err := MarshalPrivateKeyPEM(key)
+	return pemBytes, err
+}
+
+// EncodePublicKeyPEM returns pub as PKIX in a "PUBLIC KEY" PEM block.
+func EncodePublicKeyPEM(pub *PublicKey) ([]byte, error) {
+	der, err := MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  pemPublicKeyType,
+		Bytes: der,
+	}), nil
+}
+
+// DecodePrivateKeyPEM parses a private key from a single "PRIVATE KEY"
+// PEM block. Anything but whitespace after the block is an error.
+func DecodePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	der, err := decodeSinglePEM(data, pemPrivateKeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePrivateKey(der)
+}
+
+// DecodePublicKeyPEM parses a public key from a single "PUBLIC KEY" PEM
+// block. Anything but whitespace after the block is an error.
+func DecodePublicKeyPEM(data []byte) (*PublicKey, error) {
+	der, err := decodeSinglePEM(data, pemPublicKeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePublicKey(der)
+}
+
+func decodeSinglePEM(data []byte, typ string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("ecgdsa: no PEM block found")
+	}
+
+	if block.Type != typ {
+		return nil, fmt.Errorf("ecgdsa: PEM block type is %q, want %q", block.Type, typ)
+	}
+
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, errors.New("ecgdsa: trailing data after PEM block")
+	}
+
+	return block.Bytes, nil
+}
+
+// DecodeAllPrivateKeysPEM parses every "PRIVATE KEY" (PKCS#8) and
+// "EC PRIVATE KEY" (SEC 1) block in data and returns the keys in order.
+// Blocks of other types are skipped. A key block that fails to parse is an
+// error naming its index among all blocks in data.
+func DecodeAllPrivateKeysPEM(data []byte) ([]*PrivateKey, error) {
+	var keys []*PrivateKey
+
+	for index := 0; ; index++ {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			return keys, nil
+		}
+
+		var key *PrivateKey
+		var err error
+
+		switch block.Type {
+		case pemPrivateKeyType:
+			key, err = ParsePrivateKey(block.Bytes)
+		case pemECPrivateKeyType:
+			key, err = ParseSEC1PrivateKey(block.Bytes)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("ecgdsa: PEM block %d: %w", index, err)
+		}
+
+		keys = append(keys, key)
+	}
+}
+
+// DecodeAllPublicKeysPEM parses every "PUBLIC KEY" block in data and
+// returns the keys in order. Blocks of other types are skipped. A key
+// block that fails to parse is an error naming its index among all blocks
+// in data.
+func DecodeAllPublicKeysPEM(data []byte) ([]*PublicKey, error) {
+	var keys []*PublicKey
+
+	for index := 0; ; index++ {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			return keys, nil
+		}
+
+		if block.Type != pemPublicKeyType {
+			continue
+		}
+
+		key, err := ParsePublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("ecgdsa: PEM block %d: %w", index, err)
+		}
+
+		keys = append(keys, key)
+	}
+}
+
+// DecodePEMKeys reads PEM blocks from r one at a time and calls fn with
+// every decoded key: a *PrivateKey for "PRIVATE KEY" (PKCS#8) and
+// "EC PRIVATE KEY" (SEC 1) blocks, as DecodeAllPrivateKeysPEM, and a
+// *PublicKey for "PUBLIC KEY" blocks. Only one block is held in memory at
+// once, so arbitrarily large bundles can be processed. Text between
+// blocks, blocks of other types and malformed armor are skipped. A key
+// block that fails to parse, a line inside a block longer than 4096 bytes
+// (ErrPEMLineTooLong), or an error returned by fn stops decoding and is
+// returned.
+func DecodePEMKeys(r io.Reader, fn func(key interface{}) error) error {
+	br := bufio.NewReader(r)
+
+	var block []byte
+	inBlock := false
+	index := 0
+
+	for {
+		line, tooLong, err := readPEMLine(br)
+
+		switch {
+		case tooLong && inBlock:
+			return fmt.Errorf("ecgdsa: PEM block %d: %w", index, ErrPEMLineTooLong)
+		case tooLong:
+			// Garbage between blocks.
+		case len(line) > 0:
+			trimmed := bytes.TrimSpace(line)
+
+			switch {
+			case bytes.Contains(trimmed, pemBegin):
+				trimmed = trimmed[bytes.Index(trimmed, pemBegin):]
+
+				block = append(block[:0], trimmed...)
+				block = append(block, '\n')
+				inBlock = true
+
+			case inBlock && bytes.HasPrefix(trimmed, pemEnd):
+				block = append(block, trimmed...)
+				block = append(block, '\n')
+				inBlock = false
+
+				if perr := decodePEMKey(block, index, fn); perr != nil {
+					return perr
+				}
+
+				index++
+
+			case inBlock:
+				if len(block)+len(trimmed) > maxPEMBlock {
+					inBlock = false
+					break
+				}
+
+				block = append(block, trimmed...)
+				block = append(block, '\n')
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func decodePEMKey(data []byte, index int, fn func(key interface{}) error) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil
+	}
+
+	var key interface{}
+	var err error
+
+	switch block.Type {
+	case pemPrivateKeyType:
+		key, err = ParsePrivateKey(block.Bytes)
+	case pemECPrivateKeyType:
+		key, err = ParseSEC1PrivateKey(block.Bytes)
+	case pemPublicKeyType:
+		key, err = ParsePublicKey(block.Bytes)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("ecgdsa: PEM block %d: %w", index, err)
+	}
+
+	return fn(key)
+}
+
+// readPEMLine returns the next line from br including its line ending. A
+// line longer than maxPEMLine bytes is consumed but not returned, and
+// tooLong is set instead.
+func readPEMLine(br *bufio.Reader) (line []byte, tooLong bool, err error) {
+	for {
+		var frag []byte
+
+		frag, err = br.ReadSlice('\n')
+		if !tooLong && len(line)+len(frag) > maxPEMLine {
+			line, tooLong = nil, true
+		}
+
+		if !tooLong {
+			line = append(line, frag...)
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		return line, tooLong, err
+	}
+}
@@ -0,0 +1,362 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// pemBundleUnit returns PEM text with one key block of each type that
+// DecodePEMKeys reads, a block of another type, and garbage between them,
+// including a line longer than maxPEMLine.
+func pemBundleUnit(t *testing.T) []byte {
+	t.Helper()
+
+	priv := testKey(t)
+
+	pkcs8PEM, err := EncodePrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sec1PEM, err := EncodeSEC1PrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubPEM, err := EncodePublicKeyPEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("Bundle comment\n")
+	b.Write(pkcs8PEM)
+	b.WriteString(strings.Repeat("x", 2*maxPEMLine) + "\n")
+	b.Write(sec1PEM)
+	b.WriteString("garbage -----BEGIN\n")
+	b.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a certificate")}))
+	b.Write(pubPEM)
+
+	return b.Bytes()
+}
+
+func TestDecodePEMKeysBundle(t *testing.T) {
+	unit := pemBundleUnit(t)
+
+	want, err := DecodeAllPrivateKeysPEM(unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write about 4 MB through a pipe, so the bundle is never in memory
+	// as a whole.
+	units := 4 << 20 / len(unit)
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < units; i++ {
+			if _, err := pw.Write(unit); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	privs, pubs := 0, 0
+
+	err = DecodePEMKeys(pr, func(key interface{}) error {
+		switch key := key.(type) {
+		case *PrivateKey:
+			if !key.Equal(want[privs%len(want)]) {
+				t.Fatalf("private key %d differs", privs)
+			}
+			privs++
+		case *PublicKey:
+			if !key.Equal(&want[0].PublicKey) {
+				t.Fatalf("public key %d differs", pubs)
+			}
+			pubs++
+		default:
+			t.Fatalf("unexpected %T", key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if privs != units*len(want) || pubs != units {
+		t.Errorf("got %d private and %d public keys, want %d and %d", privs, pubs, units*len(want), units)
+	}
+}
+
+func TestDecodePEMKeysLongLine(t *testing.T) {
+	pubPEM, err := EncodePublicKeyPEM(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A line in the body longer than maxPEMLine must not be truncated
+	// into a different block.
+	lines := strings.SplitAfter(string(pubPEM), "\n")
+	lines[1] = strings.Repeat("A", maxPEMLine+1) + "\n"
+
+	err = DecodePEMKeys(strings.NewReader(strings.Join(lines, "")), func(interface{}) error {
+		t.Error("key decoded from a block with an overlong line")
+		return nil
+	})
+	if !errors.Is(err, ErrPEMLineTooLong) {
+		t.Errorf("got %v, want ErrPEMLineTooLong", err)
+	}
+}
+
+func TestDecodePEMKeysFnError(t *testing.T) {
+	pubPEM, err := EncodePublicKeyPEM(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+
+	err = DecodePEMKeys(bytes.NewReader(bytes.Repeat(pubPEM, 3)), func(interface{}) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("got %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestMarshalPrivateKeyPEM(t *testing.T) {
+	priv := testKey(t)
+
+	der, pemBytes, err := MarshalPrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, rest := pem.Decode(pemBytes)
+	if block == nil || len(rest) != 0 || block.Type != "PRIVATE KEY" {
+		t.Fatalf("got PEM %s", pemBytes)
+	}
+
+	if !bytes.Equal(block.Bytes, der) {
+		t.Error("PEM does not wrap the returned DER")
+	}
+
+	fromDER, err := ParsePrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromPEM, err := DecodePrivateKeyPEM(pemBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !fromDER.Equal(priv) || !fromPEM.Equal(priv) {
+		t.Error("outputs do not parse back to the key")
+	}
+
+	// The single-output functions give the same encodings.
+	single, err := MarshalPrivateKey(priv)
+	if err != nil || !bytes.Equal(single, der) {
+		t.Error("MarshalPrivateKey differs")
+	}
+
+	encoded, err := EncodePrivateKeyPEM(priv)
+	if err != nil || !bytes.Equal(encoded, pemBytes) {
+		t.Error("EncodePrivateKeyPEM differs")
+	}
+
+	if _, _, err := MarshalPrivateKeyPEM(&PrivateKey{}); err == nil {
+		t.Error("empty key marshalled")
+	}
+}
+
+func TestKeyPEMRoundTrip(t *testing.T) {
+	priv := testKey(t)
+
+	privPEM, err := EncodePrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubPEM, err := EncodePublicKeyPEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		data []byte
+		typ  string
+	}{
+		{privPEM, "PRIVATE KEY"},
+		{pubPEM, "PUBLIC KEY"},
+	} {
+		if block, _ := pem.Decode(tt.data); block == nil || block.Type != tt.typ {
+			t.Errorf("got %s, want a %q block", tt.data, tt.typ)
+		}
+	}
+
+	gotPriv, err := DecodePrivateKeyPEM(privPEM)
+	if err != nil || !gotPriv.Equal(priv) {
+		t.Errorf("private key: %v", err)
+	}
+
+	gotPub, err := DecodePublicKeyPEM(pubPEM)
+	if err != nil || !gotPub.Equal(&priv.PublicKey) {
+		t.Errorf("public key: %v", err)
+	}
+
+	// Blank lines around the block are allowed.
+	if _, err := DecodePublicKeyPEM(append(append([]byte("\n\n"), pubPEM...), "\n\t\n"...)); err != nil {
+		t.Errorf("surrounding whitespace: %v", err)
+	}
+
+	privDER, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"no block", []byte("not PEM")},
+		{"public key block", pubPEM},
+		{"EC PRIVATE KEY block", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privDER})},
+		{"second block", append(append([]byte{}, privPEM...), privPEM...)},
+		{"trailing text", append(append([]byte{}, privPEM...), "trailer\n"...)},
+		{"not a key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")})},
+	} {
+		if _, err := DecodePrivateKeyPEM(tt.data); err == nil {
+			t.Errorf("private key: %s: accepted", tt.name)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"private key block", privPEM},
+		{"second block", append(append([]byte{}, pubPEM...), pubPEM...)},
+		{"trailing text", append(append([]byte{}, pubPEM...), "trailer\n"...)},
+	} {
+		if _, err := DecodePublicKeyPEM(tt.data); err == nil {
+			t.Errorf("public key: %s: accepted", tt.name)
+		}
+	}
+
+	if _, err := EncodePublicKeyPEM(&PublicKey{}); err == nil {
+		t.Error("empty public key encoded")
+	}
+}
+
+func TestDecodeAllKeysPEM(t *testing.T) {
+	p256 := testKey(t)
+
+	p384, err := GenerateKey(rand.Reader, elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8PEM, err := EncodePrivateKeyPEM(p256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sec1PEM, err := EncodeSEC1PrivateKeyPEM(p384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub256PEM, err := EncodePublicKeyPEM(&p256.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub384PEM, err := EncodePublicKeyPEM(&p384.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a certificate")})
+
+	bundle := bytes.Join([][]byte{
+		[]byte("comment\n"), pkcs8PEM, cert, pub384PEM, sec1PEM, pub256PEM, pkcs8PEM,
+	}, nil)
+
+	privs, err := DecodeAllPrivateKeysPEM(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPrivs := []*PrivateKey{p256, p384, p256}
+	if len(privs) != len(wantPrivs) {
+		t.Fatalf("got %d private keys, want %d", len(privs), len(wantPrivs))
+	}
+
+	for i, key := range privs {
+		if !key.Equal(wantPrivs[i]) {
+			t.Errorf("private key %d differs", i)
+		}
+	}
+
+	pubs, err := DecodeAllPublicKeysPEM(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPubs := []*PublicKey{&p384.PublicKey, &p256.PublicKey}
+	if len(pubs) != len(wantPubs) {
+		t.Fatalf("got %d public keys, want %d", len(pubs), len(wantPubs))
+	}
+
+	for i, key := range pubs {
+		if !key.Equal(wantPubs[i]) {
+			t.Errorf("public key %d differs", i)
+		}
+	}
+
+	for _, data := range [][]byte{nil, []byte("no PEM here"), cert} {
+		if privs, err := DecodeAllPrivateKeysPEM(data); err != nil || len(privs) != 0 {
+			t.Errorf("%q: got %d private keys, %v", data, len(privs), err)
+		}
+
+		if pubs, err := DecodeAllPublicKeysPEM(data); err != nil || len(pubs) != 0 {
+			t.Errorf("%q: got %d public keys, %v", data, len(pubs), err)
+		}
+	}
+
+	// A key block that does not parse is reported with its index among
+	// all blocks, counting the skipped ones.
+	block, _ := pem.Decode(pkcs8PEM)
+	badPriv := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: append(block.Bytes, 0)})
+
+	block, _ = pem.Decode(pub256PEM)
+	badPub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: append(block.Bytes, 0)})
+
+	for _, tt := range []struct {
+		name   string
+		decode func([]byte) error
+		data   []byte
+	}{
+		{"private", func(data []byte) error { _, err := DecodeAllPrivateKeysPEM(data); return err }, bytes.Join([][]byte{pkcs8PEM, cert, badPriv}, nil)},
+		{"public", func(data []byte) error { _, err := DecodeAllPublicKeysPEM(data); return err }, bytes.Join([][]byte{pub256PEM, cert, badPub}, nil)},
+	} {
+		err := tt.decode(tt.data)
+		if !errors.Is(err, ErrTrailingData) || !strings.Contains(err.Error(), "PEM block 2") {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+)
+
+// PIV algorithm identifiers for EC keys, as reported by the YubiKey GET
+// METADATA command. Both map to NIST curves.
+const (
+	PIVAlgorithmECCP256 = 0x11
+	PIVAlgorithmECCP384 = 0x14
+)
+
+// PIV metadata and public key template tags.
+const (
+	pivTagAlgorithm = 0x01
+	pivTagPublicKey = 0x04
+	pivTagTemplate  = 0x7f49
+	pivTagECPoint   = 0x86
+)
+
+// ParsePIVPublicKey decodes the public key from a YubiKey PIV metadata
+// response. The response is a list of BER-TLV objects: tag 0x01 holds the
+// algorithm identifier (PIVAlgorithmECCP256 or PIVAlgorithmECCP384) and
+// tag 0x04 holds the public key, whose tag 0x86 carries the uncompressed EC
+// point. The public key may also be wrapped in the 0x7F49 template used by
+// GENERATE ASYMMETRIC KEY PAIR.
+func ParsePIVPublicKey(metadata []byte) (*PublicKey, error) {
+	if len(metadata) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	objects, err := parsePIVTLV(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, ok := objects[pivTagAlgorithm]
+	if !ok || len(alg) != 1 {
+		return nil, errors.New("ecgdsa: PIV metadata has no algorithm")
+	}
+
+	var curve elliptic.Curve
+	switch alg[0] {
+	case PIVAlgorithmECCP256:
+		curve = elliptic.P256()
+	case PIVAlgorithmECCP384:
+		curve = elliptic.P384()
+	default:
+		return nil, fmt.Errorf("ecgdsa: unsupported PIV algorithm 0x%02x", alg[0])
+	}
+
+	key, ok := objects[pivTagPublicKey]
+	if !ok {
+		return nil, errors.New("ecgdsa: PIV metadata has no public key")
+	}
+
+	inner, err := parsePIVTLV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if template, ok := inner[pivTagTemplate]; ok {
+		if inner, err = parsePIVTLV(template); err != nil {
+			return nil, err
+		}
+	}
+
+	point, ok := inner[pivTagECPoint]
+	if !ok {
+		return nil, errors.New("ecgdsa: PIV public key has no EC point")
+	}
+
+	return NewPublicKey(curve, point)
+}
+
+// parsePIVTLV splits data into BER-TLV objects keyed by tag.
+func parsePIVTLV(data []byte) (map[int][]byte, error) {
+	objects := make(map[int][]byte)
+
+	for len(data) > 0 {
+		tag := int(data[0])
+		data = data[1:]
+
+		if tag&0x1f == 0x1f {
+			if len(data) < 1 {
+				return nil, errors.New("ecgdsa: truncated PIV tag")
+			}
+
+			tag = tag<<8 | int(data[0])
+			data = data[1:]
+		}
+
+		if len(data) < 1 {
+			return nil, errors.New("ecgdsa: truncated PIV length")
+		}
+
+		length := int(data[0])
+		data = data[1:]
+
+		switch length {
+		case 0x81:
+			if len(data) < 1 {
+				return nil, errors.New("ecgdsa: truncated PIV length")
+			}
+
+			length = int(data[0])
+			data = data[1:]
+		case 0x82:
+			if len(data) < 2 {
+				return nil, errors.New("ecgdsa: truncated PIV length")
+			}
+
+			length = int(data[0])<<8 | int(data[1])
+			data = data[2:]
+		default:
+			if length > 0x7f {
+				return nil, errors.New("ecgdsa: invalid PIV length")
+			}
+		}
+
+		if len(data) < length {
+			return nil, errors.New("ecgdsa: truncated PIV value")
+		}
+
+		objects[tag] = data[:length]
+		data = data[length:]
+	}
+
+	return objects, nil
+}
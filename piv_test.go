@@ -0,0 +1,67 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// pivP256Point is the P-256 base point in uncompressed form.
+const pivP256Point = "04" +
+	"6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296" +
+	"4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"
+
+func pivHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestParsePIVPublicKey(t *testing.T) {
+	p256 := elliptic.P256().Params()
+
+	for _, tt := range []struct {
+		name     string
+		metadata string
+	}{
+		// GET METADATA on slot 9a: algorithm, PIN and touch policy,
+		// origin (generated) and the public key.
+		{"metadata", "01 01 11  02 02 01 01  03 01 01  04 43 86 41 " + pivP256Point},
+		// The same key in the 0x7F49 template of GENERATE ASYMMETRIC KEY
+		// PAIR.
+		{"template", "01 01 11  04 46 7f49 43 86 41 " + pivP256Point},
+	} {
+		pub, err := ParsePIVPublicKey(pivHex(t, tt.metadata))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if pub.Curve != elliptic.P256() || pub.X.Cmp(p256.Gx) != 0 || pub.Y.Cmp(p256.Gy) != 0 {
+			t.Errorf("%s: wrong public key", tt.name)
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		metadata string
+	}{
+		{"empty", ""},
+		{"no algorithm", "04 43 86 41 " + pivP256Point},
+		{"RSA algorithm", "01 01 07  04 43 86 41 " + pivP256Point},
+		{"P-384 algorithm", "01 01 14  04 43 86 41 " + pivP256Point},
+		{"no public key", "01 01 11  03 01 01"},
+		{"no EC point", "01 01 11  04 03 87 01 00"},
+		{"truncated", "01 01 11  04 43 86 41 04 6b17"},
+		{"off curve", "01 01 11  04 43 86 41 " + pivP256Point[:len(pivP256Point)-2] + "f6"},
+	} {
+		if _, err := ParsePIVPublicKey(pivHex(t, tt.metadata)); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ecgdsa
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// ParsePKCS11PublicKey builds a public key from the CKA_EC_POINT and
+// CKA_EC_PARAMS attributes of a PKCS#11 EC public key object.
+//
+// CKA_EC_PARAMS is the DER ECParameters, normally the curve OID.
+// CKA_EC_POINT is, per the PKCS#11 specification, the SEC 1 point wrapped
+// in a DER OCTET STRING. Some tokens omit the wrapping and return the bare
+// point, which is accepted as well. Both compressed and uncompressed points
+// are accepted, and the point must be on the curve.
+func ParsePKCS11PublicKey(ecPoint, ecParams []byte) (*PublicKey, error) {
+	if len(ecPoint) == 0 || len(ecParams) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	curve, err := parseCurveParameters(ecParams, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var point []byte
+
+	input := cryptobyte.String(ecPoint)
+	if !input.ReadASN1Bytes(&point, cbasn1.OCTET_STRING) || !input.Empty() {
+		point = ecPoint
+	}
+
+	x, y := unmarshalPoint(curve, point)
+	if x == nil {
+		// An uncompressed bare point starts with 0x04 like an OCTET
+		// STRING, so a wrapped reading that failed may still be one.
+		if x, y = unmarshalPoint(curve, ecPoint); x == nil {
+			return nil, errors.New("ecgdsa: invalid PKCS#11 EC point")
+		}
+	}
+
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
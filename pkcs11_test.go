@@ -0,0 +1,73 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestParsePKCS11PublicKey(t *testing.T) {
+	pub := &testKey(t).PublicKey
+
+	params, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uncompressed := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	compressed := elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y)
+
+	wrap := func(point []byte) []byte {
+		der, err := asn1.Marshal(point)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return der
+	}
+
+	for _, tt := range []struct {
+		name  string
+		point []byte
+	}{
+		{"wrapped uncompressed", wrap(uncompressed)},
+		{"wrapped compressed", wrap(compressed)},
+		{"bare uncompressed", uncompressed},
+		{"bare compressed", compressed},
+	} {
+		got, err := ParsePKCS11PublicKey(tt.point, params)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if !got.Equal(pub) {
+			t.Errorf("%s: wrong public key", tt.name)
+		}
+	}
+
+	offCurve := append([]byte{}, uncompressed...)
+	offCurve[len(offCurve)-1] ^= 1
+
+	p384, err := asn1.Marshal(oidNamedCurveP384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name          string
+		point, params []byte
+	}{
+		{"empty point", nil, params},
+		{"empty params", wrap(uncompressed), nil},
+		{"wrapped off curve", wrap(offCurve), params},
+		{"bare off curve", offCurve, params},
+		{"trailing data", append(wrap(uncompressed), 0), params},
+		{"wrong curve", wrap(uncompressed), p384},
+		{"bad params", wrap(uncompressed), []byte{0x06, 0x01}},
+	} {
+		if _, err := ParsePKCS11PublicKey(tt.point, tt.params); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
@@ -15,12 +15,25 @@ import (
 	"github.com/pedroalbanese/secp256k1"
 	"github.com/RyuaNerin/elliptic2/nist"
 	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
 )
 
 const ecPrivKeyVersion = 1
 
+// Errors returned, possibly wrapped with more detail, when encoding or
+// decoding keys. Use errors.Is to test for them.
+var (
+	ErrEmptyInput             = errors.New("ecgdsa: empty input")
+	ErrUnsupportedCurve       = errors.New("ecgdsa: unsupported ecgdsa curve")
+	ErrTrailingData           = errors.New("ecgdsa: trailing data")
+	ErrUnknownAlgorithm       = errors.New("ecgdsa: unknown key algorithm")
+	ErrInvalidPrivateKeyValue = errors.New("ecgdsa: invalid elliptic curve private key value")
+	ErrInvalidPublicKey       = errors.New("ecgdsa: invalid elliptic curve public key")
+)
+
 var (
 	oidPublicKeyECGDSA = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 2, 1}
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
 
 	oidNamedCurveP224 = asn1.ObjectIdentifier{1, 3, 132, 0, 33}
 	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
@@ -34,6 +47,15 @@ var (
 	oidBrainpoolP512r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 1, 1, 13}
 	oidBrainpoolP512t1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 1, 1, 14}
 
+	oidBrainpoolP160r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 1}
+	oidBrainpoolP160t1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 2}
+	oidBrainpoolP192r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 3}
+	oidBrainpoolP192t1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 4}
+	oidBrainpoolP224r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 5}
+	oidBrainpoolP224t1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 6}
+	oidBrainpoolP320r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 9}
+	oidBrainpoolP320t1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 10}
+
 	oidNamedCurveS256 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
 	oidANSSIFRP256v1 = asn1.ObjectIdentifier{1, 2, 250, 1, 223, 101, 256, 1}
 	
@@ -68,6 +90,15 @@ func init() {
 	AddNamedCurve(brainpool.P512r1(), oidBrainpoolP512r1)
 	AddNamedCurve(brainpool.P512t1(), oidBrainpoolP512t1)
 
+	AddNamedCurve(brainpool.P160r1(), oidBrainpoolP160r1)
+	AddNamedCurve(brainpool.P160t1(), oidBrainpoolP160t1)
+	AddNamedCurve(brainpool.P192r1(), oidBrainpoolP192r1)
+	AddNamedCurve(brainpool.P192t1(), oidBrainpoolP192t1)
+	AddNamedCurve(brainpool.P224r1(), oidBrainpoolP224r1)
+	AddNamedCurve(brainpool.P224t1(), oidBrainpoolP224t1)
+	AddNamedCurve(brainpool.P320r1(), oidBrainpoolP320r1)
+	AddNamedCurve(brainpool.P320t1(), oidBrainpoolP320t1)
+
 	AddNamedCurve(secp256k1.S256(), oidNamedCurveS256)
 	AddNamedCurve(frp256v1.P256(), oidANSSIFRP256v1)
 	
@@ -110,24 +141,45 @@ type publicKeyInfo struct {
 	PublicKey asn1.BitString
 }
 
-// Per RFC 5915 the NamedCurveOID is marked as ASN.1 OPTIONAL, however in
-// most cases it is not.
+// Per RFC 5915 the parameters are marked as ASN.1 OPTIONAL, however in
+// most cases they are present. They are usually a named curve OID but may
+// be any ECParameters, so the [0] element is kept raw and its contents are
+// the DER encoded parameters.
 type ecPrivateKey struct {
-	Version       int
-	PrivateKey    []byte
-	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
-	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+	Version    int
+	PrivateKey []byte
+	Parameters asn1.RawValue  `asn1:"optional,tag:0"`
+	PublicKey  asn1.BitString `asn1:"optional,explicit,tag:1"`
 }
 
 // Wrap Public Key
 func MarshalPublicKey(pub *PublicKey) ([]byte, error) {
+	return marshalPublicKey(pub, false)
+}
+
+// MarshalCompressedPublicKey is like MarshalPublicKey but stores the point
+// in compressed SEC 1 form (0x02 or 0x03 prefix and X only), which is about
+// half the size. ParsePublicKey accepts both forms.
+func MarshalCompressedPublicKey(pub *PublicKey) ([]byte, error) {
+	return marshalPublicKey(pub, true)
+}
+
+func marshalPublicKey(pub *PublicKey, compressed bool) ([]byte, error) {
 	var publicKeyBytes []byte
 	var publicKeyAlgorithm pkix.AlgorithmIdentifier
 	var err error
 
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if err = fipsCheckCurve(pub.Curve); err != nil {
+		return nil, err
+	}
+
 	oid, ok := OidFromNamedCurve(pub.Curve)
 	if !ok {
-		return nil, errors.New("ecgdsa: unsupported ecgdsa curve")
+		return nil, ErrUnsupportedCurve
 	}
 
 	var paramBytes []byte
@@ -140,10 +192,14 @@ func MarshalPublicKey(pub *PublicKey) ([]byte, error) {
 	publicKeyAlgorithm.Parameters.FullBytes = paramBytes
 
 	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
-		return nil, errors.New("ecgdsa: invalid elliptic curve public key")
+		return nil, ErrInvalidPublicKey
 	}
 
-	publicKeyBytes = elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	if compressed {
+		publicKeyBytes = elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y)
+	} else {
+		publicKeyBytes = elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	}
 
 	pkix := pkixPublicKey{
 		Algo: publicKeyAlgorithm,
@@ -156,19 +212,54 @@ func MarshalPublicKey(pub *PublicKey) ([]byte, error) {
 	return asn1.Marshal(pkix)
 }
 
-// Parse Public Key
+// Parse Public Key. The point may be uncompressed or compressed; the
+// point at infinity and hybrid encodings are rejected.
 func ParsePublicKey(derBytes []byte) (pub *PublicKey, err error) {
+	return parsePublicKey(derBytes, false, nil)
+}
+
+// ParsePublicKeyAllowEC is like ParsePublicKey but also accepts the generic
+// id-ecPublicKey algorithm (1.2.840.10045.2.1), which some tools write for
+// EC-GDSA keys since the point encoding is the same.
+//
+// Use it only for keys known to be EC-GDSA keys. The algorithm identifier
+// is what tells an EC-GDSA key from an ECDSA key on the same curve, and
+// this function discards it: an ECDSA key parsed with it never verifies
+// an ECDSA signature, and the result is written back with the EC-GDSA
+// algorithm by MarshalPublicKey.
+func ParsePublicKeyAllowEC(derBytes []byte) (*PublicKey, error) {
+	return parsePublicKey(derBytes, true, nil)
+}
+
+// ParseOptions selects what ParsePublicKeyWithOptions and
+// ParsePrivateKeyWithOptions accept beyond the defaults.
+type ParseOptions struct {
+	// AllowExplicitCurve accepts curves given by explicit domain
+	// parameters that match no registered curve. By default only named
+	// curves and explicit encodings of registered curves are accepted.
+	// Even when allowed, the curve must have a = -3, a prime field of at
+	// least 224 bits and a prime order N consistent with the field size
+	// (the Hasse bound); keys on it should still be checked with Validate.
+	AllowExplicitCurve bool
+}
+
+// ParsePublicKeyWithOptions is like ParsePublicKey with the extensions
+// selected by opts. A nil opts gives the same result as ParsePublicKey.
+func ParsePublicKeyWithOptions(derBytes []byte, opts *ParseOptions) (*PublicKey, error) {
+	return parsePublicKey(derBytes, false, opts)
+}
+
+func parsePublicKey(derBytes []byte, allowEC bool, opts *ParseOptions) (pub *PublicKey, err error) {
+	if len(derBytes) == 0 {
+		return nil, ErrEmptyInput
+	}
+
 	var pki publicKeyInfo
 	rest, err := asn1.Unmarshal(derBytes, &pki)
 	if err != nil {
 		return
 	} else if len(rest) != 0 {
-		err = errors.New("ecgdsa: trailing data after ASN.1 of public-key")
-		return
-	}
-
-	if len(rest) > 0 {
-		err = asn1.SyntaxError{Msg: "trailing data"}
+		err = fmt.Errorf("%w after ASN.1 of public-key", ErrTrailingData)
 		return
 	}
 
@@ -179,26 +270,19 @@ func ParsePublicKey(derBytes []byte) (pub *PublicKey, err error) {
 	params := keyData.Algorithm.Parameters
 	der := cryptobyte.String(keyData.PublicKey.RightAlign())
 
-	if !oid.Equal(oidPublicKeyECGDSA) {
-		err = errors.New("ecgdsa: unknown public key algorithm")
+	if !oid.Equal(oidPublicKeyECGDSA) && !(allowEC && oid.Equal(oidPublicKeyECDSA)) {
+		err = fmt.Errorf("%w %s", ErrUnknownAlgorithm, oid)
 		return
 	}
 
-	paramsDer := cryptobyte.String(params.FullBytes)
-	namedCurveOID := new(asn1.ObjectIdentifier)
-	if !paramsDer.ReadASN1ObjectIdentifier(namedCurveOID) {
-		return nil, errors.New("ecgdsa: invalid parameters")
-	}
-
-	namedCurve := NamedCurveFromOid(*namedCurveOID)
-	if namedCurve == nil {
-		err = errors.New("ecgdsa: unsupported ecgdsa curve")
-		return
+	namedCurve, err := parseCurveParameters(params.FullBytes, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	x, y := elliptic.Unmarshal(namedCurve, der)
+	x, y := unmarshalPoint(namedCurve, der)
 	if x == nil {
-		err = errors.New("ecgdsa: failed to unmarshal elliptic curve point")
+		err = fmt.Errorf("%w: failed to unmarshal point", ErrInvalidPublicKey)
 		return
 	}
 
@@ -211,21 +295,148 @@ func ParsePublicKey(derBytes []byte) (pub *PublicKey, err error) {
 	return
 }
 
+// ParsePublicKeyLax parses the public key at the start of derBytes and
+// ignores anything after it, for keys embedded in padded or framed
+// containers. Prefer ParsePublicKey: ignoring trailing bytes can hide a
+// truncated or corrupted container.
+func ParsePublicKeyLax(derBytes []byte) (*PublicKey, error) {
+	der, err := leadingElement(derBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePublicKey(der)
+}
+
+// ParsePublicKeyStrict is like ParsePublicKey but decodes the whole
+// SubjectPublicKeyInfo with cryptobyte, which only accepts DER. It
+// rejects:
+//   - indefinite lengths and lengths not in their shortest form;
+//   - object identifiers with non-minimal arcs and, in explicit curve
+//     parameters, integers with superfluous leading bytes;
+//   - a BIT STRING with unused bits, and any other tag than the ones
+//     SubjectPublicKeyInfo prescribes;
+//   - trailing data after the algorithm parameters, the key or the
+//     structure.
+func ParsePublicKeyStrict(der []byte) (*PublicKey, error) {
+	var spki, algo cryptobyte.String
+	var oid asn1.ObjectIdentifier
+	var point asn1.BitString
+
+	if len(der) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&spki, cbasn1.SEQUENCE) {
+		return nil, errors.New("ecgdsa: invalid DER public key")
+	}
+
+	if !input.Empty() {
+		return nil, fmt.Errorf("%w after ASN.1 of public-key", ErrTrailingData)
+	}
+
+	var params cryptobyte.String
+	if !spki.ReadASN1(&algo, cbasn1.SEQUENCE) ||
+		!algo.ReadASN1ObjectIdentifier(&oid) ||
+		!algo.ReadAnyASN1Element(&params, nil) ||
+		!spki.ReadASN1BitString(&point) {
+		return nil, errors.New("ecgdsa: invalid DER public key")
+	}
+
+	if !algo.Empty() || !spki.Empty() {
+		return nil, fmt.Errorf("%w in public key structure", ErrTrailingData)
+	}
+
+	if !oid.Equal(oidPublicKeyECGDSA) {
+		return nil, fmt.Errorf("%w %s", ErrUnknownAlgorithm, oid)
+	}
+
+	if point.BitLength%8 != 0 {
+		return nil, fmt.Errorf("%w: public key has unused bits", ErrInvalidPublicKey)
+	}
+
+	curve, err := parseCurveParameters(params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := unmarshalPoint(curve, point.Bytes)
+	if x == nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal point", ErrInvalidPublicKey)
+	}
+
+	return &PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// leadingElement returns the first ASN.1 element of der.
+func leadingElement(der []byte) ([]byte, error) {
+	var elem cryptobyte.String
+
+	if len(der) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	input := cryptobyte.String(der)
+	if !input.ReadASN1Element(&elem, cbasn1.SEQUENCE) {
+		return nil, ErrInvalidASN1
+	}
+
+	return elem, nil
+}
+
 // ====================
 
 // Wrap Private Key
 func MarshalPrivateKey(key *PrivateKey) ([]byte, error) {
+	return MarshalPrivateKeyWithOptions(key, nil)
+}
+
+// MarshalOptions selects optional fields written by
+// MarshalPrivateKeyWithOptions.
+type MarshalOptions struct {
+	// IncludeInnerCurveOID also writes the named curve OID into the
+	// parameters field of the inner ECPrivateKey structure, which some
+	// strict parsers expect. By default it is only in the PKCS#8
+	// AlgorithmIdentifier.
+	IncludeInnerCurveOID bool
+
+	// CompressPublicKey writes the public key embedded in the inner
+	// ECPrivateKey structure as a compressed point, which saves the
+	// byte length of the field.
+	CompressPublicKey bool
+}
+
+// MarshalPrivateKeyCompressed is like MarshalPrivateKey but embeds the
+// public key as a compressed point. ParsePrivateKey accepts both forms.
+func MarshalPrivateKeyCompressed(key *PrivateKey) ([]byte, error) {
+	return MarshalPrivateKeyWithOptions(key, &MarshalOptions{CompressPublicKey: true})
+}
+
+// MarshalPrivateKeyWithOptions is like MarshalPrivateKey with the fields
+// selected by opts. A nil opts gives the same result as MarshalPrivateKey.
+func MarshalPrivateKeyWithOptions(key *PrivateKey, opts *MarshalOptions) ([]byte, error) {
+	return marshalPKCS8(key, opts, nil)
+}
+
+// marshalPKCS8 marshals key as PKCS#8 with the given DER encoded
+// attributes.
+func marshalPKCS8(key *PrivateKey, opts *MarshalOptions, attrs []asn1.RawValue) ([]byte, error) {
+	if key == nil || key.Curve == nil || key.D == nil || key.X == nil || key.Y == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
 	var privKey pkcs8
 
 	oid, ok := OidFromNamedCurve(key.Curve)
 	if !ok {
-		return nil, errors.New("ecgdsa: unsupported ecgdsa curve")
+		return nil, ErrUnsupportedCurve
 	}
 
 	// 创建数据
 	oidBytes, err := asn1.Marshal(oid)
 	if err != nil {
-		return nil, errors.New("ecgdsa: failed to marshal algo param: " + err.Error())
+		return nil, fmt.Errorf("ecgdsa: failed to marshal algo param: %w", err)
 	}
 
 	privKey.Algo = pkix.AlgorithmIdentifier{
@@ -235,114 +446,231 @@ func MarshalPrivateKey(key *PrivateKey) ([]byte, error) {
 		},
 	}
 
-	privKey.PrivateKey, err = marshalECPrivateKeyWithOID(key, nil)
+	var innerOID asn1.ObjectIdentifier
+	if opts != nil && opts.IncludeInnerCurveOID {
+		innerOID = oid
+	}
+
+	compressed := opts != nil && opts.CompressPublicKey
+
+	privKey.PrivateKey, err = marshalECPrivateKeyWithOID(key, innerOID, compressed)
 	if err != nil {
-		return nil, errors.New("ecgdsa: failed to marshal EC private key while building PKCS#8: " + err.Error())
+		return nil, fmt.Errorf("ecgdsa: failed to marshal EC private key while building PKCS#8: %w", err)
 	}
 
+	privKey.Attributes = attrs
+
 	return asn1.Marshal(privKey)
 }
 
-// Parse Private Key
+// ParsePrivateKey parses a PKCS#8 private key. Bytes after the PKCS#8
+// structure are rejected with ErrTrailingData. This is a change from
+// earlier versions, which ignored them; callers that read keys from
+// padded or framed containers should switch to ParsePrivateKeyLax.
 func ParsePrivateKey(derBytes []byte) (*PrivateKey, error) {
-	var privKey pkcs8
-	var err error
+	return parsePrivateKey(derBytes, false)
+}
+
+// ParsePrivateKeyStrict is like ParsePrivateKey, but when the EC private
+// key carries the optional public key it must match the point computed from
+// the private scalar, or ErrKeyMismatch is returned.
+func ParsePrivateKeyStrict(derBytes []byte) (*PrivateKey, error) {
+	return parsePrivateKey(derBytes, true)
+}
 
-	_, err = asn1.Unmarshal(derBytes, &privKey)
+// ParsePrivateKeyLax parses the private key at the start of derBytes and
+// ignores anything after it. The same caveat as for ParsePublicKeyLax
+// applies.
+func ParsePrivateKeyLax(derBytes []byte) (*PrivateKey, error) {
+	der, err := leadingElement(derBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	if !privKey.Algo.Algorithm.Equal(oidPublicKeyECGDSA) {
-		err = errors.New("ecgdsa: unknown private key algorithm")
-		return nil, err
+	return ParsePrivateKey(der)
+}
+
+// ParsePrivateKeyStream parses the private key at the start of der and
+// returns the number of bytes it occupies, taken from its ASN.1 length, so
+// that a buffer of concatenated DER keys can be walked by advancing der by
+// consumed after each key.
+func ParsePrivateKeyStream(der []byte) (key *PrivateKey, consumed int, err error) {
+	elem, err := leadingElement(der)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	bytes := privKey.Algo.Parameters.FullBytes
+	key, err = ParsePrivateKey(elem)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return key, len(elem), nil
+}
+
+func parsePrivateKey(derBytes []byte, checkPublicKey bool) (*PrivateKey, error) {
+	key, _, err := parsePKCS8(derBytes, checkPublicKey, nil)
+	return key, err
+}
 
-	namedCurveOID := new(asn1.ObjectIdentifier)
-	if _, err := asn1.Unmarshal(bytes, namedCurveOID); err != nil {
-		namedCurveOID = nil
+// ParsePrivateKeyWithOptions is like ParsePrivateKey with the extensions
+// selected by opts. A nil opts gives the same result as ParsePrivateKey.
+func ParsePrivateKeyWithOptions(derBytes []byte, opts *ParseOptions) (*PrivateKey, error) {
+	key, _, err := parsePKCS8(derBytes, false, opts)
+	return key, err
+}
+
+// parsePKCS8 parses a PKCS#8 private key and also returns its attributes,
+// each the DER of one Attribute.
+func parsePKCS8(derBytes []byte, checkPublicKey bool, opts *ParseOptions) (*PrivateKey, []asn1.RawValue, error) {
+	if len(derBytes) == 0 {
+		return nil, nil, ErrEmptyInput
 	}
 
-	key, err := parseECPrivateKey(namedCurveOID, privKey.PrivateKey)
+	var privKey pkcs8
+
+	rest, err := asn1.Unmarshal(derBytes, &privKey)
 	if err != nil {
-		return nil, errors.New("ecgdsa: failed to parse EC private key embedded in PKCS#8: " + err.Error())
+		return nil, nil, err
+	} else if len(rest) != 0 {
+		return nil, nil, fmt.Errorf("%w after ASN.1 of private-key", ErrTrailingData)
+	}
+
+	if !privKey.Algo.Algorithm.Equal(oidPublicKeyECGDSA) {
+		err = fmt.Errorf("%w %s", ErrUnknownAlgorithm, privKey.Algo.Algorithm)
+		return nil, nil, err
+	}
+
+	var curve elliptic.Curve
+	if bytes := privKey.Algo.Parameters.FullBytes; len(bytes) > 0 {
+		curve, err = parseCurveParameters(bytes, opts)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return key, nil
+	key, err := parseECPrivateKey(curve, privKey.PrivateKey, checkPublicKey, opts)
+	if err == ErrKeyMismatch {
+		return nil, nil, err
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("ecgdsa: failed to parse EC private key embedded in PKCS#8: %w", err)
+	}
+
+	return key, privKey.Attributes, nil
 }
 
 // marshalECPrivateKeyWithOID marshals an SM2 private key into ASN.1, DER format and
-// sets the curve ID to the given OID, or omits it if OID is nil.
-func marshalECPrivateKeyWithOID(key *PrivateKey, oid asn1.ObjectIdentifier) ([]byte, error) {
+// sets the curve ID to the given OID, or omits it if OID is nil. The public
+// key is written compressed if compressed is set.
+func marshalECPrivateKeyWithOID(key *PrivateKey, oid asn1.ObjectIdentifier, compressed bool) ([]byte, error) {
+	if err := fipsCheckCurve(key.Curve); err != nil {
+		return nil, err
+	}
+
 	if !key.Curve.IsOnCurve(key.X, key.Y) {
-		return nil, errors.New("ecgdsa: invalid elliptic key public key")
+		return nil, ErrInvalidPublicKey
 	}
 
-	privateKey := make([]byte, BitsToBytes(key.D.BitLen()))
+	// The scalar is written at the fixed width of the order, so a D that
+	// is out of range has to be caught here rather than on parsing.
+	n := key.Curve.Params().N
+	if key.D.Sign() <= 0 || key.D.Cmp(n) >= 0 {
+		return nil, ErrInvalidPrivateKeyValue
+	}
+
+	privateKey := make([]byte, BitsToBytes(n.BitLen()))
+
+	var params asn1.RawValue
+	if oid != nil {
+		der, err := asn1.Marshal(oid)
+		if err != nil {
+			return nil, err
+		}
+
+		params = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+	}
+
+	point := elliptic.Marshal(key.Curve, key.X, key.Y)
+	if compressed {
+		point = elliptic.MarshalCompressed(key.Curve, key.X, key.Y)
+	}
 
 	return asn1.Marshal(ecPrivateKey{
-		Version:       1,
-		PrivateKey:    key.D.FillBytes(privateKey),
-		NamedCurveOID: oid,
+		Version:    1,
+		PrivateKey: key.D.FillBytes(privateKey),
+		Parameters: params,
 		PublicKey: asn1.BitString{
-			Bytes: elliptic.Marshal(key.Curve, key.X, key.Y),
+			Bytes: point,
 		},
 	})
 }
 
 // parseECPrivateKey parses an ASN.1 Elliptic Curve Private Key Structure.
-// The OID for the named curve may be provided from another source (such as
-// the PKCS8 container) - if it is provided then use this instead of the OID
-// that may exist in the EC private key structure.
-func parseECPrivateKey(namedCurveOID *asn1.ObjectIdentifier, der []byte) (key *PrivateKey, err error) {
+// The curve may be provided from another source (such as the PKCS8
+// container) - if it is provided then use this instead of the OID that may
+// exist in the EC private key structure. If checkPublicKey is set and the
+// structure carries a public key, compressed or not, it must match the
+// computed one. X and Y are always computed from the scalar.
+func parseECPrivateKey(curve elliptic.Curve, der []byte, checkPublicKey bool, opts *ParseOptions) (key *PrivateKey, err error) {
 	var privKey ecPrivateKey
 	if _, err := asn1.Unmarshal(der, &privKey); err != nil {
-		return nil, errors.New("ecgdsa: failed to parse EC private key: " + err.Error())
+		return nil, fmt.Errorf("ecgdsa: failed to parse EC private key: %w", err)
 	}
 
 	if privKey.Version != ecPrivKeyVersion {
 		return nil, fmt.Errorf("ecgdsa: unknown EC private key version %d", privKey.Version)
 	}
 
-	var curve elliptic.Curve
-	if namedCurveOID != nil {
-		curve = NamedCurveFromOid(*namedCurveOID)
-	} else {
-		curve = NamedCurveFromOid(privKey.NamedCurveOID)
-	}
-
 	if curve == nil {
-		return nil, errors.New("ecgdsa: unknown elliptic curve")
-	}
+		if len(privKey.Parameters.Bytes) == 0 {
+			return nil, fmt.Errorf("%w: no curve parameters", ErrUnsupportedCurve)
+		}
 
-	k := new(big.Int).SetBytes(privKey.PrivateKey)
+		if curve, err = parseCurveParameters(privKey.Parameters.Bytes, opts); err != nil {
+			return nil, err
+		}
+	}
 
 	curveOrder := curve.Params().N
-	if k.Cmp(curveOrder) >= 0 {
-		return nil, errors.New("ecgdsa: invalid elliptic curve private key value")
-	}
 
-	priv := new(PrivateKey)
-	priv.Curve = curve
-	priv.D = k
+	// Load the scalar into a buffer of the fixed length of the order, so
+	// the work does not depend on how many leading zeros it has. Extra
+	// leading bytes are allowed only if they are all zero.
+	raw := privKey.PrivateKey
+	privateKey := make([]byte, BitsToBytes(curveOrder.BitLen()))
 
-	privateKey := make([]byte, (curveOrder.BitLen()+7)/8)
+	if excess := len(raw) - len(privateKey); excess > 0 {
+		var nonZero byte
+		for _, b := range raw[:excess] {
+			nonZero |= b
+		}
 
-	for len(privKey.PrivateKey) > len(privateKey) {
-		if privKey.PrivateKey[0] != 0 {
-			return nil, errors.New("ecgdsa: invalid private key length")
+		if nonZero != 0 {
+			return nil, fmt.Errorf("%w: invalid private key length", ErrInvalidPrivateKeyValue)
 		}
 
-		privKey.PrivateKey = privKey.PrivateKey[1:]
+		raw = raw[excess:]
 	}
 
-	copy(privateKey[len(privateKey)-len(privKey.PrivateKey):], privKey.PrivateKey)
+	copy(privateKey[len(privateKey)-len(raw):], raw)
 
 	d := new(big.Int).SetBytes(privateKey)
+	if d.Sign() == 0 || d.Cmp(curveOrder) >= 0 {
+		return nil, ErrInvalidPrivateKeyValue
+	}
+
+	priv := new(PrivateKey)
+	priv.Curve = curve
+	priv.D = d
 	priv.X, priv.Y = XY(d, curve)
 
+	if checkPublicKey && len(privKey.PublicKey.Bytes) > 0 {
+		x, y := unmarshalPoint(curve, privKey.PublicKey.Bytes)
+		if x == nil || x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+			return nil, ErrKeyMismatch
+		}
+	}
+
 	return priv, nil
 }
 
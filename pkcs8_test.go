@@ -0,0 +1,761 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestParseEmptyInput(t *testing.T) {
+	p256Params := []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+
+	tests := []struct {
+		name  string
+		parse func(data []byte) error
+	}{
+		{"ParsePublicKey", func(data []byte) error {
+			_, err := ParsePublicKey(data)
+			return err
+		}},
+		{"ParsePublicKeyAllowEC", func(data []byte) error {
+			_, err := ParsePublicKeyAllowEC(data)
+			return err
+		}},
+		{"ParsePublicKeyWithOptions", func(data []byte) error {
+			_, err := ParsePublicKeyWithOptions(data, &ParseOptions{AllowExplicitCurve: true})
+			return err
+		}},
+		{"ParsePublicKeyLax", func(data []byte) error {
+			_, err := ParsePublicKeyLax(data)
+			return err
+		}},
+		{"ParsePublicKeyStrict", func(data []byte) error {
+			_, err := ParsePublicKeyStrict(data)
+			return err
+		}},
+		{"ParsePublicKeyXY", func(data []byte) error {
+			_, err := ParsePublicKeyXY(elliptic.P256(), data)
+			return err
+		}},
+		{"ParsePublicKeyPointLE", func(data []byte) error {
+			_, err := ParsePublicKeyPointLE(elliptic.P256(), data)
+			return err
+		}},
+		{"ParsePublicKeyMini", func(data []byte) error {
+			_, err := ParsePublicKeyMini(data)
+			return err
+		}},
+		{"ParsePIVPublicKey", func(data []byte) error {
+			_, err := ParsePIVPublicKey(data)
+			return err
+		}},
+		{"ParsePKCS11PublicKey/point", func(data []byte) error {
+			_, err := ParsePKCS11PublicKey(data, p256Params)
+			return err
+		}},
+		{"ParsePKCS11PublicKey/params", func(data []byte) error {
+			_, err := ParsePKCS11PublicKey([]byte{0x04, 0x01, 0x04}, data)
+			return err
+		}},
+		{"ParsePrivateKey", func(data []byte) error {
+			_, err := ParsePrivateKey(data)
+			return err
+		}},
+		{"ParsePrivateKeyStrict", func(data []byte) error {
+			_, err := ParsePrivateKeyStrict(data)
+			return err
+		}},
+		{"ParsePrivateKeyLax", func(data []byte) error {
+			_, err := ParsePrivateKeyLax(data)
+			return err
+		}},
+		{"ParsePrivateKeyStream", func(data []byte) error {
+			_, _, err := ParsePrivateKeyStream(data)
+			return err
+		}},
+		{"ParsePrivateKeyWithOptions", func(data []byte) error {
+			_, err := ParsePrivateKeyWithOptions(data, nil)
+			return err
+		}},
+		{"ParsePrivateKeyWithAttributes", func(data []byte) error {
+			_, _, err := ParsePrivateKeyWithAttributes(data)
+			return err
+		}},
+		{"ParsePrivateKeyWithPassword", func(data []byte) error {
+			_, err := ParsePrivateKeyWithPassword(data, []byte("secret"))
+			return err
+		}},
+		{"ParseSEC1PrivateKey", func(data []byte) error {
+			_, err := ParseSEC1PrivateKey(data)
+			return err
+		}},
+		{"ParseAnyPrivateKey", func(data []byte) error {
+			_, err := ParseAnyPrivateKey(data)
+			return err
+		}},
+		{"ParseSignature", func(data []byte) error {
+			_, err := ParseSignature(data)
+			return err
+		}},
+		{"ParseJWK", func(data []byte) error {
+			_, err := ParseJWK(data)
+			return err
+		}},
+		{"ParseVerifierBundle", func(data []byte) error {
+			_, _, err := ParseVerifierBundle(data)
+			return err
+		}},
+		{"DecodePrivateKeyPEM", func(data []byte) error {
+			_, err := DecodePrivateKeyPEM(data)
+			return err
+		}},
+		{"DecodePublicKeyPEM", func(data []byte) error {
+			_, err := DecodePublicKeyPEM(data)
+			return err
+		}},
+		{"DecodeSEC1PrivateKeyPEM", func(data []byte) error {
+			_, err := DecodeSEC1PrivateKeyPEM(data)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, data := range [][]byte{nil, {}} {
+				if err := tt.parse(data); !errors.Is(err, ErrEmptyInput) {
+					t.Errorf("got %v, want ErrEmptyInput", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBrainpoolTwisted(t *testing.T) {
+	hash := sha256.Sum256([]byte("message"))
+
+	for _, curve := range []elliptic.Curve{brainpool.P256t1(), brainpool.P384t1(), brainpool.P512t1()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			priv, err := GenerateKey(rand.Reader, curve)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			der, err := MarshalPrivateKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := ParsePrivateKey(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !parsed.Equal(priv) || parsed.Curve != curve {
+				t.Fatal("parsed private key differs")
+			}
+
+			pubDER, err := MarshalPublicKey(&priv.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pub, err := ParsePublicKey(pubDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !pub.Equal(&priv.PublicKey) || pub.Curve != curve {
+				t.Fatal("parsed public key differs")
+			}
+
+			if err := parsed.Validate(); err != nil {
+				t.Error(err)
+			}
+
+			if err := ValidatePKCS8(der); err != nil {
+				t.Error(err)
+			}
+
+			sig, err := SignASN1(rand.Reader, parsed, hash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !VerifyASN1(pub, hash[:], sig) {
+				t.Error("signature does not verify")
+			}
+		})
+	}
+}
+
+func TestParseTrailingData(t *testing.T) {
+	priv := testKey(t)
+
+	privDER, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubDER, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	padding := []byte{0, 0, 0, 0}
+
+	if _, err := ParsePrivateKey(append(privDER, padding...)); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("ParsePrivateKey: got %v, want ErrTrailingData", err)
+	}
+
+	if _, err := ParsePublicKey(append(pubDER, padding...)); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("ParsePublicKey: got %v, want ErrTrailingData", err)
+	}
+
+	key, err := ParsePrivateKeyLax(append(privDER, padding...))
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyLax: %v", err)
+	}
+
+	if !key.Equal(priv) {
+		t.Error("ParsePrivateKeyLax: key differs")
+	}
+
+	pub, err := ParsePublicKeyLax(append(pubDER, padding...))
+	if err != nil {
+		t.Fatalf("ParsePublicKeyLax: %v", err)
+	}
+
+	if !pub.Equal(&priv.PublicKey) {
+		t.Error("ParsePublicKeyLax: key differs")
+	}
+}
+
+func TestParseECPrivateKeyLeadingZeros(t *testing.T) {
+	curve := elliptic.P256()
+
+	for _, d := range []*big.Int{
+		big.NewInt(1),
+		big.NewInt(0xff),
+		new(big.Int).Lsh(big.NewInt(5), 200),
+		new(big.Int).Sub(curve.Params().N, big.NewInt(1)),
+	} {
+		for _, scalar := range [][]byte{
+			d.Bytes(),
+			d.FillBytes(make([]byte, 32)),
+			d.FillBytes(make([]byte, 34)),
+		} {
+			der, err := asn1.Marshal(ecPrivateKey{Version: ecPrivKeyVersion, PrivateKey: scalar})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := parseECPrivateKey(curve, der, false, nil)
+			if err != nil {
+				t.Fatalf("d = %x, %d bytes: %v", d, len(scalar), err)
+			}
+
+			if key.D.Cmp(d) != 0 {
+				t.Errorf("d = %x, %d bytes: got %x", d, len(scalar), key.D)
+			}
+		}
+	}
+
+	// Extra leading bytes must be zero.
+	scalar := append([]byte{1}, big.NewInt(1).FillBytes(make([]byte, 32))...)
+
+	der, err := asn1.Marshal(ecPrivateKey{Version: ecPrivKeyVersion, PrivateKey: scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parseECPrivateKey(curve, der, false, nil); !errors.Is(err, ErrInvalidPrivateKeyValue) {
+		t.Errorf("got %v, want ErrInvalidPrivateKeyValue", err)
+	}
+}
+
+func TestParsePublicKeyTrailingGarbage(t *testing.T) {
+	der, err := MarshalPublicKey(&testKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, garbage := range [][]byte{{0xde, 0xad, 0xbe, 0xef}, {0x05, 0x00}, {0x00}} {
+		data := append(append([]byte{}, der...), garbage...)
+
+		if _, err := ParsePublicKey(data); !errors.Is(err, ErrTrailingData) {
+			t.Errorf("%x: got %v, want ErrTrailingData", garbage, err)
+		}
+	}
+}
+
+func TestPrivateKeyCompressedPublicKey(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPrivateKeyCompressed(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p8 pkcs8
+	if _, err := asn1.Unmarshal(der, &p8); err != nil {
+		t.Fatal(err)
+	}
+
+	var inner ecPrivateKey
+	if _, err := asn1.Unmarshal(p8.PrivateKey, &inner); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := elliptic.MarshalCompressed(priv.Curve, priv.X, priv.Y); !bytes.Equal(inner.PublicKey.Bytes, want) {
+		t.Fatal("embedded public key is not the compressed point")
+	}
+
+	// Without the embedded public key X and Y come from D alone.
+	inner.PublicKey = asn1.BitString{}
+
+	noPub := p8
+	if noPub.PrivateKey, err = asn1.Marshal(inner); err != nil {
+		t.Fatal(err)
+	}
+
+	noPubDER, err := asn1.Marshal(noPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"compressed": der, "absent": noPubDER} {
+		for _, parse := range []func([]byte) (*PrivateKey, error){ParsePrivateKey, ParsePrivateKeyStrict} {
+			key, err := parse(data)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if key.X.Cmp(priv.X) != 0 || key.Y.Cmp(priv.Y) != 0 || key.D.Cmp(priv.D) != 0 {
+				t.Errorf("%s: key differs", name)
+			}
+		}
+
+		if err := ValidatePKCS8(data); err != nil {
+			t.Errorf("%s: ValidatePKCS8: %v", name, err)
+		}
+	}
+}
+
+func TestMarshalPrivateKeyScalarWidth(t *testing.T) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	// A small D is still written at the 32-byte width of the order.
+	priv, err := NewPrivateKeyFromScalar(curve, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p8 pkcs8
+	if _, err := asn1.Unmarshal(der, &p8); err != nil {
+		t.Fatal(err)
+	}
+
+	var inner ecPrivateKey
+	if _, err := asn1.Unmarshal(p8.PrivateKey, &inner); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := big.NewInt(1).FillBytes(make([]byte, 32)); !bytes.Equal(inner.PrivateKey, want) {
+		t.Errorf("scalar is %x, want %x", inner.PrivateKey, want)
+	}
+
+	for _, d := range []*big.Int{new(big.Int), n, new(big.Int).Add(n, big.NewInt(1))} {
+		tampered := *testKey(t)
+		tampered.D = d
+
+		if _, err := MarshalPrivateKey(&tampered); !errors.Is(err, ErrInvalidPrivateKeyValue) {
+			t.Errorf("D = %x: got %v, want ErrInvalidPrivateKeyValue", d, err)
+		}
+	}
+}
+
+func TestParsePrivateKeyStrictMismatch(t *testing.T) {
+	priv, other := testKey(t), testKey(t)
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p8 pkcs8
+	if _, err := asn1.Unmarshal(der, &p8); err != nil {
+		t.Fatal(err)
+	}
+
+	var inner ecPrivateKey
+	if _, err := asn1.Unmarshal(p8.PrivateKey, &inner); err != nil {
+		t.Fatal(err)
+	}
+
+	notOnCurve := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+	notOnCurve[len(notOnCurve)-1] ^= 1
+
+	for _, tt := range []struct {
+		name  string
+		point []byte
+	}{
+		{"other key", elliptic.Marshal(other.Curve, other.X, other.Y)},
+		{"other key compressed", elliptic.MarshalCompressed(other.Curve, other.X, other.Y)},
+		{"not on curve", notOnCurve},
+	} {
+		inner.PublicKey = asn1.BitString{Bytes: tt.point, BitLength: 8 * len(tt.point)}
+
+		mismatched := p8
+		if mismatched.PrivateKey, err = asn1.Marshal(inner); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := asn1.Marshal(mismatched)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ParsePrivateKeyStrict(data); !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("%s: ParsePrivateKeyStrict got %v, want ErrKeyMismatch", tt.name, err)
+		}
+
+		// Without the check the public key is recomputed from D.
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			t.Fatalf("%s: ParsePrivateKey: %v", tt.name, err)
+		}
+
+		if !key.Equal(priv) {
+			t.Errorf("%s: ParsePrivateKey returned another key", tt.name)
+		}
+	}
+
+	if _, err := ParsePrivateKeyStrict(der); err != nil {
+		t.Errorf("matching public key: %v", err)
+	}
+}
+
+func TestBrainpoolSmallCurves(t *testing.T) {
+	hash := sha256.Sum256([]byte("message"))
+
+	tests := []struct {
+		curve elliptic.Curve
+		oid   asn1.ObjectIdentifier
+	}{
+		{brainpool.P160r1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 1}},
+		{brainpool.P160t1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 2}},
+		{brainpool.P192r1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 3}},
+		{brainpool.P192t1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 4}},
+		{brainpool.P320r1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 9}},
+		{brainpool.P320t1(), asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.oid.String(), func(t *testing.T) {
+			if oid, ok := OidFromNamedCurve(test.curve); !ok || !oid.Equal(test.oid) {
+				t.Fatalf("OidFromNamedCurve = %v, %v", oid, ok)
+			}
+
+			if NamedCurveFromOid(test.oid) != test.curve {
+				t.Fatal("NamedCurveFromOid returned another curve")
+			}
+
+			priv, err := GenerateKey(rand.Reader, test.curve)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pubDER, err := MarshalPublicKey(&priv.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oidDER, err := asn1.Marshal(test.oid)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Contains(pubDER, oidDER) {
+				t.Error("public key does not name the curve OID")
+			}
+
+			pub, err := ParsePublicKey(pubDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !pub.Equal(&priv.PublicKey) || pub.Curve != test.curve {
+				t.Fatal("parsed public key differs")
+			}
+
+			der, err := MarshalPrivateKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := ParsePrivateKey(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !parsed.Equal(priv) || parsed.Curve != test.curve {
+				t.Fatal("parsed private key differs")
+			}
+
+			sig, err := SignASN1(rand.Reader, parsed, hash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !VerifyASN1(pub, hash[:], sig) {
+				t.Error("signature does not verify")
+			}
+
+			hash[0] ^= 1
+			if VerifyASN1(pub, hash[:], sig) {
+				t.Error("signature verifies for another hash")
+			}
+			hash[0] ^= 1
+		})
+	}
+}
+
+func TestParsePrivateKeyStream(t *testing.T) {
+	var keys []*PrivateKey
+	var ders [][]byte
+	var stream []byte
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P521(), brainpool.P384r1()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		der, err := MarshalPrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys = append(keys, priv)
+		ders = append(ders, der)
+		stream = append(stream, der...)
+	}
+
+	for i, offset := 0, 0; offset < len(stream); i++ {
+		key, consumed, err := ParsePrivateKeyStream(stream[offset:])
+		if err != nil {
+			t.Fatalf("key %d at offset %d: %v", i, offset, err)
+		}
+
+		if consumed != len(ders[i]) {
+			t.Errorf("key %d: consumed %d bytes, want %d", i, consumed, len(ders[i]))
+		}
+
+		if !key.Equal(keys[i]) {
+			t.Errorf("key %d differs", i)
+		}
+
+		offset += consumed
+	}
+
+	for _, tt := range []struct {
+		name string
+		in   []byte
+	}{
+		{"empty", nil},
+		{"truncated", stream[:len(ders[0])-1]},
+		{"not a key", []byte{0x30, 0x03, 0x02, 0x01, 0x00}},
+		{"garbage", []byte{0xff, 0xff}},
+	} {
+		if key, consumed, err := ParsePrivateKeyStream(tt.in); err == nil || key != nil || consumed != 0 {
+			t.Errorf("%s: got %v, %d, %v", tt.name, key, consumed, err)
+		}
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	priv := testKey(t)
+
+	pubDER, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sec1DER, err := MarshalSEC1PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustMarshal := func(v interface{}) []byte {
+		der, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	p256Param := asn1.RawValue{FullBytes: mustMarshal(oidNamedCurveP256)}
+	unknownParam := asn1.RawValue{FullBytes: mustMarshal(asn1.ObjectIdentifier{1, 2, 3, 4})}
+	point := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+
+	spki := func(algo asn1.ObjectIdentifier, param asn1.RawValue, point []byte) []byte {
+		return mustMarshal(pkixPublicKey{
+			Algo:      pkix.AlgorithmIdentifier{Algorithm: algo, Parameters: param},
+			BitString: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+		})
+	}
+
+	pkcs8Key := func(algo asn1.ObjectIdentifier, param asn1.RawValue, d []byte) []byte {
+		return mustMarshal(pkcs8{
+			Algo:       pkix.AlgorithmIdentifier{Algorithm: algo, Parameters: param},
+			PrivateKey: mustMarshal(ecPrivateKey{Version: 1, PrivateKey: d}),
+		})
+	}
+
+	offCurve := append([]byte{}, point...)
+	offCurve[len(offCurve)-1] ^= 1
+
+	d := priv.D.FillBytes(make([]byte, 32))
+	rsaOID := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+	parsePub := func(der []byte) error { _, err := ParsePublicKey(der); return err }
+	parsePriv := func(der []byte) error { _, err := ParsePrivateKey(der); return err }
+	parseSEC1 := func(der []byte) error { _, err := ParseSEC1PrivateKey(der); return err }
+
+	for _, tt := range []struct {
+		name  string
+		parse func([]byte) error
+		der   []byte
+		want  error
+	}{
+		{"public key, trailing data", parsePub, append(append([]byte{}, pubDER...), 0), ErrTrailingData},
+		{"public key, RSA", parsePub, spki(rsaOID, asn1.NullRawValue, point), ErrUnknownAlgorithm},
+		{"public key, unknown curve", parsePub, spki(oidPublicKeyECGDSA, unknownParam, point), ErrUnsupportedCurve},
+		{"public key, off curve", parsePub, spki(oidPublicKeyECGDSA, p256Param, offCurve), ErrInvalidPublicKey},
+		{"private key, trailing data", parsePriv, append(append([]byte{}, privDER...), 0), ErrTrailingData},
+		{"private key, RSA", parsePriv, pkcs8Key(rsaOID, asn1.NullRawValue, d), ErrUnknownAlgorithm},
+		{"private key, unknown curve", parsePriv, pkcs8Key(oidPublicKeyECGDSA, unknownParam, d), ErrUnsupportedCurve},
+		{"private key, zero scalar", parsePriv, pkcs8Key(oidPublicKeyECGDSA, p256Param, make([]byte, 32)), ErrInvalidPrivateKeyValue},
+		{"private key, scalar of N", parsePriv, pkcs8Key(oidPublicKeyECGDSA, p256Param, elliptic.P256().Params().N.Bytes()), ErrInvalidPrivateKeyValue},
+		{"SEC 1 key, trailing data", parseSEC1, append(append([]byte{}, sec1DER...), 0), ErrTrailingData},
+	} {
+		err := tt.parse(tt.der)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+
+		// The wrapped message keeps the package prefix.
+		if msg := err.Error(); len(msg) < 8 || msg[:8] != "ecgdsa: " {
+			t.Errorf("%s: message %q", tt.name, msg)
+		}
+	}
+
+	// The sentinels are distinct classes.
+	sentinels := []error{ErrUnsupportedCurve, ErrTrailingData, ErrUnknownAlgorithm, ErrInvalidPrivateKeyValue, ErrInvalidPublicKey}
+	for i, a := range sentinels {
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("%v matches %v", a, b)
+			}
+		}
+	}
+
+	unregistered := &PublicKey{Curve: &countCurve{Curve: elliptic.P256()}, X: priv.X, Y: priv.Y}
+	if _, err := MarshalPublicKey(unregistered); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("MarshalPublicKey on an unregistered curve: got %v", err)
+	}
+}
+
+func TestParsePublicKeyAllowEC(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edDER, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("message"))
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		name := curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The EC-GDSA point written by a tool that labels it as an
+		// ECDSA key.
+		ecDER, err := x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: curve, X: priv.X, Y: priv.Y})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ecgdsaDER, err := MarshalPublicKey(&priv.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ParsePublicKey(ecDER); !errors.Is(err, ErrUnknownAlgorithm) {
+			t.Errorf("%s: ParsePublicKey: got %v, want ErrUnknownAlgorithm", name, err)
+		}
+
+		for _, der := range [][]byte{ecDER, ecgdsaDER} {
+			pub, err := ParsePublicKeyAllowEC(der)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if !pub.Equal(&priv.PublicKey) {
+				t.Errorf("%s: key differs", name)
+			}
+
+			// The key is written back with the EC-GDSA algorithm.
+			again, err := MarshalPublicKey(pub)
+			if err != nil || !bytes.Equal(again, ecgdsaDER) {
+				t.Errorf("%s: re-marshalled key differs", name)
+			}
+
+			sig, err := SignASN1(rand.Reader, priv, hash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !VerifyASN1(pub, hash[:], sig) {
+				t.Errorf("%s: signature rejected", name)
+			}
+		}
+	}
+
+	for _, parse := range []func([]byte) (*PublicKey, error){ParsePublicKey, ParsePublicKeyAllowEC} {
+		if _, err := parse(edDER); !errors.Is(err, ErrUnknownAlgorithm) {
+			t.Errorf("Ed25519 key: got %v, want ErrUnknownAlgorithm", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ecgdsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// pkcs8Attribute is an Attribute of the PKCS#8 attributes field:
+//
+//	Attribute ::= SEQUENCE {
+//	  type   OBJECT IDENTIFIER,
+//	  values SET SIZE(1..MAX) OF ANY
+//	}
+type pkcs8Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// MarshalPrivateKeyWithAttributes is like MarshalPrivateKey and also
+// writes attrs to the PKCS#8 attributes field, for example a PKCS#9
+// friendlyName or a creation time. Each Value is encoded with asn1.Marshal,
+// so an asn1.RawValue, as returned by ParsePrivateKeyWithAttributes, is
+// written unchanged. Consecutive entries of the same Type become the
+// values of a single Attribute.
+func MarshalPrivateKeyWithAttributes(key *PrivateKey, attrs []pkix.AttributeTypeAndValue) ([]byte, error) {
+	var raw []asn1.RawValue
+
+	for i := 0; i < len(attrs); {
+		attr := pkcs8Attribute{Type: attrs[i].Type}
+
+		for ; i < len(attrs) && attrs[i].Type.Equal(attr.Type); i++ {
+			value, err := asn1.Marshal(attrs[i].Value)
+			if err != nil {
+				return nil, fmt.Errorf("ecgdsa: failed to marshal PKCS#8 attribute %s: %w", attr.Type, err)
+			}
+
+			attr.Values = append(attr.Values, asn1.RawValue{FullBytes: value})
+		}
+
+		der, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, err
+		}
+
+		raw = append(raw, asn1.RawValue{FullBytes: der})
+	}
+
+	return marshalPKCS8(key, nil, raw)
+}
+
+// ParsePrivateKeyWithAttributes is like ParsePrivateKey and also returns
+// the PKCS#8 attributes, one entry per attribute value in the order of the
+// encoding. Each Value is the asn1.RawValue of the encoded value, which
+// MarshalPrivateKeyWithAttributes writes back byte for byte.
+func ParsePrivateKeyWithAttributes(derBytes []byte) (*PrivateKey, []pkix.AttributeTypeAndValue, error) {
+	key, raw, err := parsePKCS8(derBytes, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var attrs []pkix.AttributeTypeAndValue
+
+	for _, r := range raw {
+		var attr pkcs8Attribute
+
+		rest, err := asn1.Unmarshal(r.FullBytes, &attr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ecgdsa: invalid PKCS#8 attribute: %w", err)
+		} else if len(rest) != 0 {
+			return nil, nil, fmt.Errorf("%w after PKCS#8 attribute", ErrTrailingData)
+		}
+
+		if len(attr.Values) == 0 {
+			return nil, nil, errors.New("ecgdsa: PKCS#8 attribute has no value")
+		}
+
+		for _, value := range attr.Values {
+			attrs = append(attrs, pkix.AttributeTypeAndValue{Type: attr.Type, Value: value})
+		}
+	}
+
+	return key, attrs, nil
+}
@@ -0,0 +1,76 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+)
+
+// TestPrivateKeyAttributesRoundTrip parses a PKCS#8 key whose attributes
+// are not in DER SET OF order, one of them with two values, and checks
+// that MarshalPrivateKeyWithAttributes writes the same bytes back.
+func TestPrivateKeyAttributesRoundTrip(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p8 pkcs8
+	if _, err := asn1.Unmarshal(der, &p8); err != nil {
+		t.Fatal(err)
+	}
+
+	attribute := func(oid asn1.ObjectIdentifier, values ...interface{}) asn1.RawValue {
+		attr := pkcs8Attribute{Type: oid}
+		for _, v := range values {
+			b, err := asn1.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			attr.Values = append(attr.Values, asn1.RawValue{FullBytes: b})
+		}
+
+		b, err := asn1.Marshal(attr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return asn1.RawValue{FullBytes: b}
+	}
+
+	// localKeyID (9.21) sorts after friendlyName (9.20) but comes first.
+	p8.Attributes = []asn1.RawValue{
+		attribute(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}, []byte{0x01, 0x02}),
+		attribute(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}, "label a", "label b"),
+		attribute(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 25, 3}, 1700000000),
+	}
+
+	want, err := asn1.Marshal(p8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, attrs, err := ParsePrivateKeyWithAttributes(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !key.Equal(priv) {
+		t.Error("parsed key differs")
+	}
+
+	if len(attrs) != 4 {
+		t.Fatalf("got %d attribute values, want 4", len(attrs))
+	}
+
+	got, err := MarshalPrivateKeyWithAttributes(key, attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("round trip changed the encoding:\ngot  %x\nwant %x", got, want)
+	}
+}
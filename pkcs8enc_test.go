@@ -0,0 +1,296 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readPEMFixture returns the DER of the single PEM block in
+// testdata/name.
+func readPEMFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("%s: no PEM block", name)
+	}
+
+	return block.Bytes
+}
+
+var testEncryptOptions = &EncryptOptions{Iterations: 1000}
+
+func TestPrivateKeyWithPasswordRoundTrip(t *testing.T) {
+	priv := testKey(t)
+
+	for _, opts := range []*EncryptOptions{
+		testEncryptOptions,
+		{Iterations: 1000, KeySize: 16, PRF: crypto.SHA1},
+		{Iterations: 1000, KeySize: 24, PRF: crypto.SHA512},
+	} {
+		der, err := MarshalPrivateKeyWithPassword(priv, []byte("secret"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ParsePrivateKeyWithPassword(der, []byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Equal(priv) {
+			t.Errorf("%+v: decrypted key differs", opts)
+		}
+	}
+}
+
+func TestPrivateKeyWithPasswordWrongPassword(t *testing.T) {
+	der, err := MarshalPrivateKeyWithPassword(testKey(t), []byte("secret"), testEncryptOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, password := range []string{"", "Secret", "secret2", "wrong password"} {
+		if _, err := ParsePrivateKeyWithPassword(der, []byte(password)); !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("password %q: got %v, want ErrIncorrectPassword", password, err)
+		}
+	}
+}
+
+func TestPrivateKeyWithPasswordIterationCap(t *testing.T) {
+	_, err := MarshalPrivateKeyWithPassword(testKey(t), []byte("secret"), &EncryptOptions{Iterations: MaxPBKDF2Iterations + 1})
+	if !errors.Is(err, ErrIterationCountTooHigh) {
+		t.Errorf("got %v, want ErrIterationCountTooHigh", err)
+	}
+}
+
+// TestPrivateKeyWithPasswordOpenSSL decrypts a P-256 ECDSA key written
+// by
+//
+//	openssl pkcs8 -topk8 -v2 aes-256-cbc -iter 2048 -passout pass:secret
+//
+// in testdata/ecdsa-p256-aes256cbc.pem; testdata/ecdsa-p256.pem is the
+// same key from -nocrypt.
+func TestPrivateKeyWithPasswordOpenSSL(t *testing.T) {
+	der := readPEMFixture(t, "ecdsa-p256-aes256cbc.pem")
+	want := readPEMFixture(t, "ecdsa-p256.pem")
+
+	plain, err := decryptPKCS8(der, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(plain, want) {
+		t.Fatal("decrypted key differs from the OpenSSL plaintext")
+	}
+
+	// The plaintext is an ECDSA key: that must be reported as such, not
+	// as a wrong password.
+	_, err = ParsePrivateKeyWithPassword(der, []byte("secret"))
+	if !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("got %v, want ErrUnknownAlgorithm", err)
+	}
+
+	if _, err := ParsePrivateKeyWithPassword(der, []byte("Secret")); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("got %v, want ErrIncorrectPassword", err)
+	}
+}
+
+// TestPrivateKeyWithPasswordOpenSSLECGDSA decrypts the EC-GDSA key of
+// testdata/ecgdsa-p256.pem, encrypted with OpenSSL. "openssl pkcs8" does
+// not load EC-GDSA keys, so testdata/ecgdsa-p256-aes256cbc.pem was made
+// from its parts: the key from "openssl kdf ... -kdfopt iter:2048 PBKDF2"
+// with HMAC-SHA256 and password "secret", the ciphertext from
+// "openssl enc -aes-256-cbc", and the EncryptedPrivateKeyInfo from
+// "openssl asn1parse -genconf". "openssl pkcs8" decrypts it and then
+// stops at the unsupported algorithm.
+func TestPrivateKeyWithPasswordOpenSSLECGDSA(t *testing.T) {
+	want, err := ParsePrivateKey(readPEMFixture(t, "ecgdsa-p256.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der := readPEMFixture(t, "ecgdsa-p256-aes256cbc.pem")
+
+	got, err := ParsePrivateKeyWithPassword(der, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(want) {
+		t.Error("decrypted key differs from the plaintext fixture")
+	}
+
+	if _, err := ParsePrivateKeyWithPassword(der, []byte("Secret")); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("got %v, want ErrIncorrectPassword", err)
+	}
+}
+
+// TestPrivateKeyWithPasswordMalformed checks that a malformed structure is
+// not reported as a wrong password.
+func TestPrivateKeyWithPasswordMalformed(t *testing.T) {
+	der := readPEMFixture(t, "ecgdsa-p256-aes256cbc.pem")
+
+	// edit returns der with its structure changed by fn.
+	edit := func(fn func(info *encryptedPrivateKeyInfo, params *pbes2Params)) []byte {
+		var info encryptedPrivateKeyInfo
+		if _, err := asn1.Unmarshal(der, &info); err != nil {
+			t.Fatal(err)
+		}
+
+		var params pbes2Params
+		if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+			t.Fatal(err)
+		}
+
+		fn(&info, &params)
+
+		b, err := asn1.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info.Algo.Parameters = asn1.RawValue{FullBytes: b}
+
+		if b, err = asn1.Marshal(info); err != nil {
+			t.Fatal(err)
+		}
+
+		return b
+	}
+
+	for _, tt := range []struct {
+		name string
+		der  []byte
+	}{
+		{"truncated", der[:len(der)-1]},
+		{"trailing data", append(append([]byte{}, der...), 0)},
+		{"not PBES2", edit(func(info *encryptedPrivateKeyInfo, _ *pbes2Params) {
+			info.Algo.Algorithm = oidPBKDF2
+		})},
+		{"unknown cipher", edit(func(_ *encryptedPrivateKeyInfo, params *pbes2Params) {
+			params.EncryptionScheme.Algorithm = oidPBKDF2
+		})},
+		{"short IV", edit(func(_ *encryptedPrivateKeyInfo, params *pbes2Params) {
+			params.EncryptionScheme.Parameters = asn1.RawValue{FullBytes: []byte{0x04, 0x01, 0x00}}
+		})},
+		{"partial block", edit(func(info *encryptedPrivateKeyInfo, _ *pbes2Params) {
+			info.EncryptedData = info.EncryptedData[:len(info.EncryptedData)-1]
+		})},
+		{"unknown KDF", edit(func(_ *encryptedPrivateKeyInfo, params *pbes2Params) {
+			params.KeyDerivationFunc.Algorithm = asn1.RawValue{FullBytes: []byte{0x06, 0x03, 0x2a, 0x03, 0x04}}
+		})},
+	} {
+		_, err := ParsePrivateKeyWithPassword(tt.der, []byte("secret"))
+		if err == nil || errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("%s: got %v, want a structure error", tt.name, err)
+		}
+	}
+}
+
+var testArgon2idOptions = &EncryptOptions{Argon2id: &Argon2idOptions{Time: 1, Memory: 64, Parallelism: 2}}
+
+func TestPrivateKeyWithPasswordArgon2id(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPrivateKeyWithPassword(priv, []byte("secret"), testArgon2idOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParsePrivateKeyWithPassword(der, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(priv) {
+		t.Error("decrypted key differs")
+	}
+
+	for _, password := range []string{"", "Secret", "secret2"} {
+		if _, err := ParsePrivateKeyWithPassword(der, []byte(password)); !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("password %q: got %v, want ErrIncorrectPassword", password, err)
+		}
+	}
+}
+
+func TestPrivateKeyWithPasswordArgon2idCost(t *testing.T) {
+	for _, opts := range []Argon2idOptions{
+		{Time: MaxArgon2Time + 1},
+		{Memory: MaxArgon2Memory + 1},
+	} {
+		_, err := MarshalPrivateKeyWithPassword(testKey(t), []byte("secret"), &EncryptOptions{Argon2id: &opts})
+		if !errors.Is(err, ErrArgon2CostTooHigh) {
+			t.Errorf("%+v: got %v, want ErrArgon2CostTooHigh", opts, err)
+		}
+	}
+
+	// The stored costs are checked before any key derivation.
+	der, err := asn1.Marshal(argon2idParams{Salt: make([]byte, 16), Time: 1, Memory: MaxArgon2Memory + 1, Parallelism: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := argon2idKeyFromParams(der, []byte("secret"), 32); !errors.Is(err, ErrArgon2CostTooHigh) {
+		t.Errorf("got %v, want ErrArgon2CostTooHigh", err)
+	}
+}
+
+// TestOIDArgon2id checks that oidArgon2id encodes 2.25.<UUID> and that it
+// is the key derivation function written by MarshalPrivateKeyWithPassword.
+func TestOIDArgon2id(t *testing.T) {
+	uuid, ok := new(big.Int).SetString("8d9987f252324128945799449254af69", 16)
+	if !ok {
+		t.Fatal("bad UUID")
+	}
+
+	var raw asn1.RawValue
+	if rest, err := asn1.Unmarshal(oidArgon2id, &raw); err != nil || len(rest) != 0 || raw.Tag != asn1.TagOID {
+		t.Fatalf("oidArgon2id is not an OBJECT IDENTIFIER: %v", err)
+	}
+
+	// 105 is the first subidentifier 2*40 + 25; the UUID follows in base 128.
+	if raw.Bytes[0] != 105 {
+		t.Fatalf("oidArgon2id is not under 2.25")
+	}
+
+	arc := new(big.Int)
+	for _, b := range raw.Bytes[1:] {
+		arc.Lsh(arc, 7)
+		arc.Or(arc, big.NewInt(int64(b&0x7f)))
+	}
+
+	if arc.Cmp(uuid) != 0 {
+		t.Errorf("oidArgon2id arc is %s, want %s", arc, uuid)
+	}
+
+	der, err := MarshalPrivateKeyWithPassword(testKey(t), []byte("secret"), testArgon2idOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		t.Fatal(err)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(params.KeyDerivationFunc.Algorithm.FullBytes, oidArgon2id) {
+		t.Error("key derivation function is not oidArgon2id")
+	}
+}
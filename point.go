@@ -0,0 +1,90 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"sync"
+)
+
+// primeFields caches isPrimeField for registered curves.
+var primeFields sync.Map // elliptic.Curve -> bool
+
+// isPrimeField reports whether curve is defined over a prime field. The
+// sect* curves are binary curves whose Params hold the reduction
+// polynomial in P, so the prime field arithmetic used here does not apply
+// to them. The primality test runs once per registered curve.
+func isPrimeField(curve elliptic.Curve) bool {
+	if prime, ok := primeFields.Load(curve); ok {
+		return prime.(bool)
+	}
+
+	p := curve.Params().P
+	prime := p.Sign() > 0 && p.ProbablyPrime(20)
+
+	if _, ok := OidFromNamedCurve(curve); ok {
+		primeFields.Store(curve, prime)
+	}
+
+	return prime
+}
+
+// unmarshalCompressed decodes a SEC1 compressed point on a prime curve in
+// short Weierstrass form. Unlike elliptic.UnmarshalCompressed it does not
+// assume a = -3, so it also handles the brainpool and secp256k1 curves.
+// Curves over a binary field are rejected. It returns nil on error.
+func unmarshalCompressed(curve elliptic.Curve, data []byte) (x, y *big.Int) {
+	if !isPrimeField(curve) {
+		return nil, nil
+	}
+
+	params := curve.Params()
+	byteLen := (params.BitSize + 7) / 8
+
+	if len(data) != 1+byteLen || (data[0] != 2 && data[0] != 3) {
+		return nil, nil
+	}
+
+	p := params.P
+
+	x = new(big.Int).SetBytes(data[1:])
+	if x.Cmp(p) >= 0 {
+		return nil, nil
+	}
+
+	a := curveA(params)
+	if a == nil {
+		return nil, nil
+	}
+
+	// y² = x³ + ax + b
+	y2 := new(big.Int).Mul(x, x)
+	y2.Add(y2, a)
+	y2.Mul(y2, x)
+	y2.Add(y2, params.B)
+	y2.Mod(y2, p)
+
+	y = new(big.Int).ModSqrt(y2, p)
+	if y == nil {
+		return nil, nil
+	}
+
+	if byte(y.Bit(0)) != data[0]&1 {
+		y.Sub(p, y)
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, nil
+	}
+
+	return x, y
+}
+
+// unmarshalPoint decodes a SEC 1 point in compressed or uncompressed form.
+// It returns nil on error.
+func unmarshalPoint(curve elliptic.Curve, data []byte) (x, y *big.Int) {
+	if len(data) > 0 && data[0] == 4 {
+		return elliptic.Unmarshal(curve, data)
+	}
+
+	return unmarshalCompressed(curve, data)
+}
@@ -0,0 +1,58 @@
+package ecgdsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+)
+
+// TestUnmarshalCompressedBinaryCurve checks that a compressed point on
+// sect283k1, a curve over a binary field, is rejected rather than
+// decompressed with prime field arithmetic.
+func TestUnmarshalCompressedBinaryCurve(t *testing.T) {
+	curve := NamedCurveFromOid(oidSect283k1)
+	if curve == nil {
+		t.Fatal("sect283k1 not registered")
+	}
+
+	if isPrimeField(curve) {
+		t.Fatal("sect283k1 reported as a prime field curve")
+	}
+
+	params := curve.Params()
+	point := make([]byte, 1+BitsToBytes(params.BitSize))
+	point[0] = 2
+	params.Gx.FillBytes(point[1:])
+
+	if x, _ := unmarshalPoint(curve, point); x != nil {
+		t.Error("unmarshalPoint accepted a compressed sect283k1 point")
+	}
+
+	oid, err := asn1.Marshal(oidSect283k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := asn1.Marshal(pkixPublicKey{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECGDSA,
+			Parameters: asn1.RawValue{FullBytes: oid},
+		},
+		BitString: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParsePublicKey(der); err == nil {
+		t.Error("ParsePublicKey accepted a compressed sect283k1 point")
+	}
+}
+
+func TestIsPrimeField(t *testing.T) {
+	for _, oid := range []asn1.ObjectIdentifier{oidNamedCurveP256, oidBrainpoolP256r1, oidNamedCurveS256} {
+		if curve := NamedCurveFromOid(oid); !isPrimeField(curve) {
+			t.Errorf("%s: not reported as a prime field curve", oid)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// precomputeWindow is the window width in bits of the PrecomputedSigner
+// table.
+const precomputeWindow = 4
+
+// PrecomputedSigner signs with one private key using a table of multiples
+// of the base point, so each kG takes one mixed Jacobian addition per
+// window of k and a single inversion instead of a full double-and-add.
+//
+// The table holds j·2^(4i)·G for j = 1..16 and every 4-bit window i of the
+// order, about 64 KiB for a 256-bit curve. The nonce is recoded so that
+// every window has a digit from 1 to 16: each window costs exactly one
+// addition, and the entry is read by scanning the whole row with
+// crypto/subtle, so neither the sequence of operations nor the memory
+// accessed depends on the nonce. The big.Int field arithmetic is still not
+// constant time, as with the generic curves of crypto/elliptic.
+//
+// P-224, P-256, P-384 and P-521 never use the table: their
+// ScalarBaseMult is constant time and already uses optimized fixed-base
+// tables, so for them PrecomputedSigner is the plain signing path. So is
+// it for the binary sect* curves, which the prime field table cannot
+// handle.
+type PrecomputedSigner struct {
+	priv *PrivateKey
+	a    *big.Int
+
+	// table[i][j-1] is j·2^(4i)·G as fixed-width x || y.
+	table [][][]byte
+
+	// offset is the sum of 2^(4i) over all windows, modulo N.
+	offset *big.Int
+}
+
+type affinePoint struct {
+	x, y *big.Int
+}
+
+// NewPrecomputedSigner builds the table for priv.
+func NewPrecomputedSigner(priv *PrivateKey) (*PrecomputedSigner, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	curve := priv.Curve
+	params := curve.Params()
+
+	if isConstantTimeCurve(curve) || !isPrimeField(curve) {
+		return &PrecomputedSigner{priv: priv}, nil
+	}
+
+	a := curveA(params)
+	if a == nil {
+		return nil, errors.New("ecgdsa: cannot precompute for this curve")
+	}
+
+	size := (params.BitSize + 7) / 8
+	windows := (params.N.BitLen() + precomputeWindow - 1) / precomputeWindow
+	table := make([][][]byte, windows)
+	offset := new(big.Int)
+
+	bx, by := params.Gx, params.Gy
+	for i := range table {
+		row := make([][]byte, 1<<precomputeWindow)
+
+		x, y := bx, by
+		for j := range row {
+			if j > 0 {
+				x, y = curve.Add(x, y, bx, by)
+			}
+
+			row[j] = make([]byte, 2*size)
+			x.FillBytes(row[j][:size])
+			y.FillBytes(row[j][size:])
+		}
+
+		table[i] = row
+		bx, by = x, y
+
+		offset.Add(offset, new(big.Int).Lsh(big.NewInt(1), uint(precomputeWindow*i)))
+	}
+
+	offset.Mod(offset, params.N)
+
+	return &PrecomputedSigner{priv: priv, a: a, table: table, offset: offset}, nil
+}
+
+// isConstantTimeCurve reports whether curve has the parameters of one of
+// the crypto/elliptic curves with a constant-time implementation.
+func isConstantTimeCurve(curve elliptic.Curve) bool {
+	return sameCurve(curve, elliptic.P224()) || sameCurve(curve, elliptic.P256()) ||
+		sameCurve(curve, elliptic.P384()) || sameCurve(curve, elliptic.P521())
+}
+
+// Sign signs the hash value hash and returns the ASN.1 encoded signature,
+// the same as PrivateKey.Sign with a pre-hashed digest.
+func (ps *PrecomputedSigner) Sign(rand io.Reader, hash []byte) ([]byte, error) {
+	r, s, err := signDigestWithBaseMult(ps.priv, hash, func() (*big.Int, error) {
+		return randFieldElement(rand, ps.priv.Curve)
+	}, ps.baseMult)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSignature(r, s)
+}
+
+// baseMult returns kG from the table. k - offset mod N is split into
+// 4-bit digits and each digit is raised by one, which adds the offset back,
+// so every window selects one of the 16 entries.
+func (ps *PrecomputedSigner) baseMult(k []byte) (x, y *big.Int) {
+	if ps.table == nil {
+		return ps.priv.Curve.ScalarBaseMult(k)
+	}
+
+	params := ps.priv.Curve.Params()
+	p := params.P
+
+	e := new(big.Int).SetBytes(k)
+	e.Sub(e, ps.offset)
+	e.Mod(e, params.N)
+
+	scalar := e.FillBytes(make([]byte, (len(ps.table)+1)/2))
+
+	size := len(ps.table[0][0]) / 2
+	entry := make([]byte, 2*size)
+	acc := newJacobianPoint()
+
+	for i, row := range ps.table {
+		// Window i is bits 4i to 4i+3 of the scalar, counted from the end.
+		w := int(scalar[len(scalar)-1-i/2]>>(4*(i%2))) & 0x0f
+
+		for j := range row {
+			subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(j), int32(w)), entry, row[j])
+		}
+
+		acc.addAffine(affinePoint{
+			x: new(big.Int).SetBytes(entry[:size]),
+			y: new(big.Int).SetBytes(entry[size:]),
+		}, ps.a, p)
+	}
+
+	return acc.affine(p)
+}
+
+// jacobianPoint is (X : Y : Z) with x = X/Z² and y = Y/Z³; Z = 0 is the
+// point at infinity.
+type jacobianPoint struct {
+	x, y, z *big.Int
+}
+
+func newJacobianPoint() *jacobianPoint {
+	return &jacobianPoint{new(big.Int), new(big.Int), new(big.Int)}
+}
+
+// addAffine sets q = q + (x2, y2), using the mixed addition formulas
+// add-2007-bl and, when both points are equal, doubling with a general a
+// (dbl-2007-bl).
+func (q *jacobianPoint) addAffine(pt affinePoint, a, p *big.Int) {
+	if q.z.Sign() == 0 {
+		q.x.Set(pt.x)
+		q.y.Set(pt.y)
+		q.z.SetInt64(1)
+		return
+	}
+
+	z1z1 := new(big.Int).Mul(q.z, q.z)
+	z1z1.Mod(z1z1, p)
+
+	u2 := new(big.Int).Mul(pt.x, z1z1)
+	u2.Mod(u2, p)
+
+	s2 := new(big.Int).Mul(pt.y, q.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+
+	h := new(big.Int).Sub(u2, q.x)
+	h.Mod(h, p)
+
+	r := new(big.Int).Sub(s2, q.y)
+	r.Mod(r, p)
+
+	if h.Sign() == 0 {
+		if r.Sign() == 0 {
+			q.double(a, p)
+		} else {
+			q.z.SetInt64(0)
+		}
+		return
+	}
+
+	hh := new(big.Int).Mul(h, h)
+	hh.Mod(hh, p)
+
+	hhh := new(big.Int).Mul(h, hh)
+	hhh.Mod(hhh, p)
+
+	v := new(big.Int).Mul(q.x, hh)
+	v.Mod(v, p)
+
+	// X3 = r² - HHH - 2V
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, hhh)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+	x3.Mod(x3, p)
+
+	// Y3 = r(V - X3) - Y1·HHH
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	hhh.Mul(hhh, q.y)
+	y3.Sub(y3, hhh)
+	y3.Mod(y3, p)
+
+	// Z3 = Z1·H
+	q.z.Mul(q.z, h)
+	q.z.Mod(q.z, p)
+
+	q.x, q.y = x3, y3
+}
+
+// double sets q = 2q.
+func (q *jacobianPoint) double(a, p *big.Int) {
+	if q.z.Sign() == 0 || q.y.Sign() == 0 {
+		q.z.SetInt64(0)
+		return
+	}
+
+	xx := new(big.Int).Mul(q.x, q.x)
+	xx.Mod(xx, p)
+
+	yy := new(big.Int).Mul(q.y, q.y)
+	yy.Mod(yy, p)
+
+	yyyy := new(big.Int).Mul(yy, yy)
+	yyyy.Mod(yyyy, p)
+
+	zz := new(big.Int).Mul(q.z, q.z)
+	zz.Mod(zz, p)
+
+	// S = 4·X·YY
+	s := new(big.Int).Mul(q.x, yy)
+	s.Lsh(s, 2)
+	s.Mod(s, p)
+
+	// M = 3·XX + a·ZZ²
+	m := new(big.Int).Mul(zz, zz)
+	m.Mul(m, a)
+	m.Add(m, xx)
+	m.Add(m, xx)
+	m.Add(m, xx)
+	m.Mod(m, p)
+
+	// X3 = M² - 2S
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, s)
+	x3.Sub(x3, s)
+	x3.Mod(x3, p)
+
+	// Y3 = M(S - X3) - 8·YYYY
+	y3 := new(big.Int).Sub(s, x3)
+	y3.Mul(y3, m)
+	yyyy.Lsh(yyyy, 3)
+	y3.Sub(y3, yyyy)
+	y3.Mod(y3, p)
+
+	// Z3 = 2·Y·Z
+	q.z.Mul(q.z, q.y)
+	q.z.Lsh(q.z, 1)
+	q.z.Mod(q.z, p)
+
+	q.x, q.y = x3, y3
+}
+
+// affine returns the affine coordinates of q, or (0, 0) for the point at
+// infinity.
+func (q *jacobianPoint) affine(p *big.Int) (x, y *big.Int) {
+	if q.z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	zInv := new(big.Int).ModInverse(q.z, p)
+
+	zInv2 := new(big.Int).Mul(zInv, zInv)
+	zInv2.Mod(zInv2, p)
+
+	x = new(big.Int).Mul(q.x, zInv2)
+	x.Mod(x, p)
+
+	zInv2.Mul(zInv2, zInv)
+	y = new(big.Int).Mul(q.y, zInv2)
+	y.Mod(y, p)
+
+	return x, y
+}
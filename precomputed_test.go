@@ -0,0 +1,76 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestPrecomputedSignerBaseMult(t *testing.T) {
+	// P-256 with base point 2G is not one of the crypto/elliptic curves,
+	// so it uses the table.
+	params := *elliptic.P256().Params()
+	params.Name = "P-256/2G"
+	params.Gx, params.Gy = elliptic.P256().Double(params.Gx, params.Gy)
+
+	priv, err := GenerateKey(rand.Reader, &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := NewPrecomputedSigner(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ps.table == nil {
+		t.Fatal("no table for a custom curve")
+	}
+
+	n := params.N
+	for _, k := range []*big.Int{
+		big.NewInt(1),
+		big.NewInt(16),
+		new(big.Int).Sub(n, big.NewInt(1)),
+		new(big.Int).Rsh(n, 1),
+		ps.offset,
+	} {
+		x, y := ps.baseMult(k.Bytes())
+		wx, wy := params.ScalarBaseMult(k.Bytes())
+
+		if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+			t.Errorf("k = %x: wrong result", k)
+		}
+	}
+
+	hash := sha256.Sum256([]byte("message"))
+
+	sig, err := ps.Sign(rand.Reader, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !VerifyASN1(&priv.PublicKey, hash[:], sig) {
+		t.Error("signature does not verify")
+	}
+}
+
+func TestPrecomputedSignerNISTCurves(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		priv, err := GenerateKey(rand.Reader, curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ps, err := NewPrecomputedSigner(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ps.table != nil {
+			t.Errorf("%s: uses the table", curve.Params().Name)
+		}
+	}
+}
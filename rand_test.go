@@ -0,0 +1,69 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// outOfRangeReader returns only 0xff bytes, which make every candidate
+// scalar larger than the order of the NIST curves, and counts them.
+type outOfRangeReader struct {
+	n int
+}
+
+func (r *outOfRangeReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0xff
+	}
+
+	r.n += len(p)
+
+	return len(p), nil
+}
+
+func TestRandExhausted(t *testing.T) {
+	defer func(n int) { MaxRandAttempts = n }(MaxRandAttempts)
+
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	for _, attempts := range []int{1000, 5} {
+		MaxRandAttempts = attempts
+
+		for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P521()} {
+			rand := &outOfRangeReader{}
+
+			if _, err := GenerateKey(rand, curve); !errors.Is(err, ErrRandExhausted) {
+				t.Errorf("%s: got %v, want ErrRandExhausted", curve.Params().Name, err)
+			}
+
+			if want := attempts * BitsToBytes(curve.Params().N.BitLen()); rand.n != want {
+				t.Errorf("%s: read %d bytes, want %d", curve.Params().Name, rand.n, want)
+			}
+		}
+
+		rand := &outOfRangeReader{}
+
+		if _, err := SignASN1(rand, priv, hash[:]); !errors.Is(err, ErrRandExhausted) {
+			t.Errorf("SignASN1: got %v, want ErrRandExhausted", err)
+		}
+
+		if rand.n != attempts*32 {
+			t.Errorf("SignASN1: read %d bytes, want %d", rand.n, attempts*32)
+		}
+
+		// A nonce source that only gives k = 0, for which r = 0, is
+		// retried MaxRandAttempts times as well.
+		calls := 0
+		_, _, err := signDigestWithNonce(priv, hash[:], func() (*big.Int, error) {
+			calls++
+			return new(big.Int), nil
+		})
+		if !errors.Is(err, ErrRandExhausted) || calls != attempts {
+			t.Errorf("zero nonce: got %v after %d nonces, want ErrRandExhausted after %d", err, calls, attempts)
+		}
+	}
+}
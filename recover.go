@@ -0,0 +1,111 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var ErrNoRecovery = errors.New("ecgdsa: no public key for this recovery id")
+
+// RecoverPublicKey recovers the public key that produced the raw r || s
+// signature sig of the hash value hash, as returned by SignRawRecoverable.
+//
+// r is the x coordinate of the nonce point R = kG reduced mod N. The
+// recovery id selects R among the candidates for r: bit 0 is the parity of
+// the y coordinate and bit 1 is set when the x coordinate is r + N rather
+// than r. Since s = d(kr - h) and Y = d⁻¹G, the key is
+//
+//	Y = s⁻¹(rR - hG)
+//
+// The recovered key is verified against the signature. Every valid
+// candidate R gives some key that verifies, so only the signer's recovery
+// id gives the signer's key; ErrNoRecovery is returned if no point exists
+// for the recovery id.
+func RecoverPublicKey(curve elliptic.Curve, hash, sig []byte, recoveryID int) (*PublicKey, error) {
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if recoveryID < 0 || recoveryID > 3 {
+		return nil, errors.New("ecgdsa: recovery id must be in [0, 3]")
+	}
+
+	r, s, err := RawCodec.Decode(sig, curve)
+	if err != nil {
+		return nil, err
+	}
+
+	params := curve.Params()
+	n := params.N
+
+	if !checkSignatureRange(curve, r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Candidate R from its x coordinate and y parity.
+	x := new(big.Int).Set(r)
+	if recoveryID&2 != 0 {
+		x.Add(x, n)
+	}
+
+	if x.Cmp(params.P) >= 0 {
+		return nil, ErrNoRecovery
+	}
+
+	byteLen := (params.BitSize + 7) / 8
+	compressed := make([]byte, 1+byteLen)
+	compressed[0] = 2 | byte(recoveryID&1)
+	x.FillBytes(compressed[1:])
+
+	rx, ry := unmarshalCompressed(curve, compressed)
+	if rx == nil {
+		return nil, ErrNoRecovery
+	}
+
+	// Y = s⁻¹r·R + s⁻¹(-h)·G
+	sInv := new(big.Int).ModInverse(s, n)
+
+	u := new(big.Int).Mul(sInv, r)
+	u.Mod(u, n)
+
+	v := hashToInt(hash, n)
+	v.Neg(v)
+	v.Mul(v, sInv)
+	v.Mod(v, n)
+
+	x1, y1 := curve.ScalarMult(rx, ry, u.Bytes())
+	x2, y2 := curve.ScalarBaseMult(v.Bytes())
+	yx, yy := curve.Add(x1, y1, x2, y2)
+
+	if yx.Sign() == 0 && yy.Sign() == 0 {
+		return nil, ErrNoRecovery
+	}
+
+	pub := &PublicKey{Curve: curve, X: yx, Y: yy}
+	if !verifyDigest(pub, hash, r, s) {
+		return nil, ErrNoRecovery
+	}
+
+	return pub, nil
+}
+
+// SignRawRecoverable is SignRaw that also returns the recovery id to pass
+// to RecoverPublicKey, so a signature and one extra byte can stand in for
+// the signature and the public key.
+func SignRawRecoverable(rand io.Reader, priv *PrivateKey, hash []byte) (sig []byte, recoveryID int, err error) {
+	sig, err = SignRaw(rand, priv, hash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for id := 0; id < 4; id++ {
+		pub, err := RecoverPublicKey(priv.Curve, hash, sig, id)
+		if err == nil && pub.X.Cmp(priv.X) == 0 && pub.Y.Cmp(priv.Y) == 0 {
+			return sig, id, nil
+		}
+	}
+
+	return nil, 0, ErrNoRecovery
+}
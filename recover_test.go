@@ -0,0 +1,71 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRecoverPublicKey(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+
+	for i := 0; i < 16; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+
+		sig, id, err := SignRawRecoverable(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != 0 && id != 1 {
+			t.Fatalf("got recovery id %d on P-256", id)
+		}
+
+		got, err := RecoverPublicKey(priv.Curve, hash[:], sig, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Equal(pub) {
+			t.Errorf("message %d: id %d recovered another key", i, id)
+		}
+
+		// The other parity gives the key of -R, which verifies but is not
+		// the signer's.
+		other, err := RecoverPublicKey(priv.Curve, hash[:], sig, id^1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other.Equal(pub) {
+			t.Errorf("message %d: id %d recovered the signer's key", i, id^1)
+		}
+
+		// r + N is larger than P on P-256, so ids 2 and 3 have no point.
+		for _, id := range []int{2, 3} {
+			if _, err := RecoverPublicKey(priv.Curve, hash[:], sig, id); !errors.Is(err, ErrNoRecovery) {
+				t.Errorf("message %d: id %d: got %v, want ErrNoRecovery", i, id, err)
+			}
+		}
+	}
+
+	hash := sha256.Sum256([]byte("message"))
+
+	sig, _, err := SignRawRecoverable(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int{-1, 4} {
+		if _, err := RecoverPublicKey(priv.Curve, hash[:], sig, id); err == nil {
+			t.Errorf("id %d accepted", id)
+		}
+	}
+
+	if _, err := RecoverPublicKey(priv.Curve, hash[:], make([]byte, len(sig)), 0); err == nil {
+		t.Error("zero signature accepted")
+	}
+}
@@ -0,0 +1,227 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const registryExportVersion = 1
+
+// registryExport is the structure written by ExportRegistry:
+//
+//	RegistryExport ::= SEQUENCE {
+//	  version INTEGER (1),
+//	  curves  SEQUENCE OF CurveEntry
+//	}
+//
+//	CurveEntry ::= SEQUENCE {
+//	  name UTF8String,
+//	  oid  OBJECT IDENTIFIER,
+//	  p, a, b, gx, gy, n INTEGER
+//	}
+type registryExport struct {
+	Version int
+	Curves  []curveEntry
+}
+
+type curveEntry struct {
+	Name               string `asn1:"utf8"`
+	OID                asn1.ObjectIdentifier
+	P, A, B, Gx, Gy, N *big.Int
+}
+
+// ExportRegistry serializes the registered curves with their names, OIDs
+// and domain parameters, so that the configuration can be saved or shipped
+// to other services and restored with ImportRegistry. CurveEntry can only
+// describe curves over a prime field, so the binary sect* curves are left
+// out; they are built into the package and registered everywhere anyway.
+func ExportRegistry() ([]byte, error) {
+	export := registryExport{Version: registryExportVersion}
+
+	curves := registeredCurves()
+	for i := range curves {
+		cur := &curves[i]
+		if !isPrimeField(cur.namedCurve) {
+			continue
+		}
+
+		params := cur.namedCurve.Params()
+
+		a := curveA(params)
+		if a == nil {
+			return nil, fmt.Errorf("ecgdsa: cannot export curve %s", params.Name)
+		}
+
+		export.Curves = append(export.Curves, curveEntry{
+			Name: params.Name,
+			OID:  cur.oid,
+			P:    params.P,
+			A:    a,
+			B:    params.B,
+			Gx:   params.Gx,
+			Gy:   params.Gy,
+			N:    params.N,
+		})
+	}
+
+	return asn1.Marshal(export)
+}
+
+// ImportRegistry registers the curves of a configuration written by
+// ExportRegistry. The whole input is validated before anything is
+// registered: the field and order of every entry must be prime, the
+// generator must be on the curve, and no OID may appear twice. An entry
+// whose OID is already registered with the same parameters is skipped; an
+// OID registered with other parameters, or parameters registered under
+// another OID, is an error. New curves must have a = -3, since
+// elliptic.CurveParams only implements that case. All problems are
+// returned together and nothing is registered if there is any; otherwise
+// every new curve is registered under a single lock, so that no lookup
+// sees part of the configuration.
+func ImportRegistry(data []byte) error {
+	if len(data) == 0 {
+		return ErrEmptyInput
+	}
+
+	var export registryExport
+
+	rest, err := asn1.Unmarshal(data, &export)
+	if err != nil {
+		return err
+	} else if len(rest) != 0 {
+		return errors.New("ecgdsa: trailing data after registry export")
+	}
+
+	if export.Version != registryExportVersion {
+		return fmt.Errorf("ecgdsa: unknown registry export version %d", export.Version)
+	}
+
+	var errs []error
+
+	params := make([]*specifiedCurve, len(export.Curves))
+
+	for i, entry := range export.Curves {
+		var err error
+
+		params[i], err = checkCurveEntry(entry)
+
+		for j := 0; err == nil && j < i; j++ {
+			if export.Curves[j].OID.Equal(entry.OID) {
+				err = fmt.Errorf("OID %s also used by entry %d", entry.OID, j)
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ecgdsa: registry entry %d (%s): %w", i, entry.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var todo []namedCurveInfo
+
+	for i, entry := range export.Curves {
+		curve, err := importCurveLocked(entry, params[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ecgdsa: registry entry %d (%s): %w", i, entry.Name, err))
+			continue
+		}
+
+		if curve != nil {
+			todo = append(todo, namedCurveInfo{namedCurve: curve, oid: entry.OID})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if len(todo) > 0 && registryFrozen.Load() {
+		return ErrRegistryFrozen
+	}
+
+	namedCurves = append(namedCurves, todo...)
+
+	return nil
+}
+
+// checkCurveEntry validates the parameters of entry and returns them as a
+// specifiedCurve.
+func checkCurveEntry(entry curveEntry) (*specifiedCurve, error) {
+	if entry.P == nil || entry.A == nil || entry.B == nil ||
+		entry.Gx == nil || entry.Gy == nil || entry.N == nil {
+		return nil, errors.New("missing parameters")
+	}
+
+	p := entry.P
+
+	if p.Sign() <= 0 || !p.ProbablyPrime(20) {
+		return nil, errors.New("field is not prime")
+	}
+
+	if entry.N.Sign() <= 0 || !entry.N.ProbablyPrime(20) {
+		return nil, errors.New("order is not prime")
+	}
+
+	for _, v := range []*big.Int{entry.A, entry.B, entry.Gx, entry.Gy} {
+		if v.Sign() < 0 || v.Cmp(p) >= 0 {
+			return nil, errors.New("parameter out of range")
+		}
+	}
+
+	// y² = x³ + ax + b
+	lhs := new(big.Int).Mul(entry.Gy, entry.Gy)
+	lhs.Mod(lhs, p)
+
+	rhs := new(big.Int).Mul(entry.Gx, entry.Gx)
+	rhs.Add(rhs, entry.A)
+	rhs.Mul(rhs, entry.Gx)
+	rhs.Add(rhs, entry.B)
+	rhs.Mod(rhs, p)
+
+	if lhs.Cmp(rhs) != 0 {
+		return nil, errors.New("generator is not on the curve")
+	}
+
+	byteLen := BitsToBytes(p.BitLen())
+	base := make([]byte, 1+2*byteLen)
+	base[0] = 4
+	entry.Gx.FillBytes(base[1 : 1+byteLen])
+	entry.Gy.FillBytes(base[1+byteLen:])
+
+	return &specifiedCurve{P: p, A: entry.A, B: entry.B, N: entry.N, Base: base}, nil
+}
+
+// importCurveLocked returns the curve to register for entry, or nil if it
+// is already registered. registryMu must be held.
+func importCurveLocked(entry curveEntry, params *specifiedCurve) (elliptic.Curve, error) {
+	for i := range namedCurves {
+		cur := &namedCurves[i]
+
+		if cur.oid.Equal(entry.OID) {
+			if !curveHasParams(cur.namedCurve, params) {
+				return nil, fmt.Errorf("OID %s is registered with other parameters", entry.OID)
+			}
+
+			return nil, nil
+		}
+	}
+
+	for i := range namedCurves {
+		cur := &namedCurves[i]
+
+		if curveHasParams(cur.namedCurve, params) {
+			return nil, fmt.Errorf("curve is already registered as %s", cur.oid)
+		}
+	}
+
+	return customCurve(entry.Name, params)
+}
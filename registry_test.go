@@ -0,0 +1,401 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// raceTestRuns numbers the runs of TestRegistryConcurrent, so that each
+// run registers new names and OIDs under go test -count.
+var raceTestRuns atomic.Int32
+
+// TestRegistryConcurrent registers curves, hashes and codecs while other
+// goroutines look them up. Run it with go test -race.
+func TestRegistryConcurrent(t *testing.T) {
+	const n = 20
+
+	run := int(raceTestRuns.Add(1))
+	oid := func(i int) asn1.ObjectIdentifier {
+		return asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, run, i}
+	}
+	name := func(i int) string {
+		return fmt.Sprintf("race-test-%d-%d", run, i)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			params := *elliptic.P256().Params()
+			params.Name = name(i)
+
+			if err := RegisterCurve(&params, oid(i)); err != nil {
+				t.Error(err)
+			}
+
+			RegisterHash(crypto.SHA256, asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1})
+			RegisterSignatureCodec(name(i), RawCodec)
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+
+			NamedCurveFromOid(oid(i))
+			OidFromNamedCurve(elliptic.P256())
+			SupportedHashes()
+			SignatureCodecByName(name(i))
+			SupportedSignatureFormats()
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if _, ok := SignatureCodecByName(name(i)); !ok {
+			t.Errorf("codec %s not registered", name(i))
+		}
+
+		if NamedCurveFromOid(oid(i)) == nil {
+			t.Errorf("curve %s not registered", name(i))
+		}
+	}
+}
+
+// p256Entry returns a registry entry for P-256 with base point k·G, which
+// matches no registered curve for k > 1. The tests here use k > 10, so that
+// the curves they register do not affect other tests.
+func p256Entry(k int64, oid asn1.ObjectIdentifier) curveEntry {
+	curve := elliptic.P256()
+	params := curve.Params()
+	gx, gy := curve.ScalarBaseMult(big.NewInt(k).Bytes())
+
+	return curveEntry{
+		Name: fmt.Sprintf("P-256/%dG", k),
+		OID:  oid,
+		P:    params.P,
+		A:    new(big.Int).Sub(params.P, big.NewInt(3)),
+		B:    params.B,
+		Gx:   gx,
+		Gy:   gy,
+		N:    params.N,
+	}
+}
+
+func marshalRegistryExport(t *testing.T, entries ...curveEntry) []byte {
+	t.Helper()
+
+	der, err := asn1.Marshal(registryExport{Version: registryExportVersion, Curves: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return der
+}
+
+func TestImportRegistry(t *testing.T) {
+	der, err := ExportRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything is already registered with the same parameters.
+	if err := ImportRegistry(der); err != nil {
+		t.Fatal(err)
+	}
+
+	oid1 := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 1}
+	oid2 := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 2}
+
+	if err := ImportRegistry(marshalRegistryExport(t, p256Entry(12, oid1), p256Entry(13, oid2))); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, oid := range []asn1.ObjectIdentifier{oid1, oid2} {
+		if NamedCurveFromOid(oid) == nil {
+			t.Errorf("%s not registered", oid)
+		}
+	}
+
+	// Importing the same entries again is a no-op.
+	if err := ImportRegistry(marshalRegistryExport(t, p256Entry(12, oid1), p256Entry(13, oid2))); err != nil {
+		t.Error(err)
+	}
+
+	// Other parameters under a registered OID are rejected.
+	if err := ImportRegistry(marshalRegistryExport(t, p256Entry(14, oid1))); err == nil {
+		t.Error("conflicting parameters accepted")
+	}
+}
+
+func TestImportRegistryDuplicateOID(t *testing.T) {
+	oid := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 3}
+
+	if err := ImportRegistry(marshalRegistryExport(t, p256Entry(15, oid), p256Entry(16, oid))); err == nil {
+		t.Fatal("duplicate OID accepted")
+	}
+
+	if NamedCurveFromOid(oid) != nil {
+		t.Error("curve registered despite the error")
+	}
+}
+
+func TestImportRegistryAtomic(t *testing.T) {
+	good := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 4}
+	bad := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 5}
+
+	composite := p256Entry(18, bad)
+	composite.N = new(big.Int).Add(composite.N, big.NewInt(1))
+
+	if err := ImportRegistry(marshalRegistryExport(t, p256Entry(17, good), composite)); err == nil {
+		t.Fatal("composite order accepted")
+	}
+
+	if NamedCurveFromOid(good) != nil || NamedCurveFromOid(bad) != nil {
+		t.Error("curve registered despite the error")
+	}
+}
+
+func TestExportRegistrySkipsBinaryCurves(t *testing.T) {
+	der, err := ExportRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var export registryExport
+	if _, err := asn1.Unmarshal(der, &export); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range export.Curves {
+		if entry.OID.Equal(oidSect283k1) {
+			t.Errorf("binary curve %s exported", entry.Name)
+		}
+	}
+}
+
+// registryRoundTripEnv selects the step of TestExportImportFreshRegistry
+// that a child test process runs, and registryRoundTripDir the directory
+// of the exported files.
+const (
+	registryRoundTripEnv = "ECGDSA_REGISTRY_ROUND_TRIP"
+	registryRoundTripDir = "ECGDSA_REGISTRY_ROUND_TRIP_DIR"
+)
+
+// TestExportImportFreshRegistry exports the registry of one process with
+// an extra curve, imports it into another fresh process, and checks that
+// the second process then exports the same configuration. Each step runs
+// in its own test binary, since the registry cannot be reset in-process.
+func TestExportImportFreshRegistry(t *testing.T) {
+	extra := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 6}
+
+	if step := os.Getenv(registryRoundTripEnv); step != "" {
+		registryRoundTripStep(t, step, os.Getenv(registryRoundTripDir), extra)
+		return
+	}
+
+	dir := t.TempDir()
+
+	for _, step := range []string{"export", "import"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestExportImportFreshRegistry$")
+		cmd.Env = append(os.Environ(), registryRoundTripEnv+"="+step, registryRoundTripDir+"="+dir)
+
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s: %v\n%s", step, err, out)
+		}
+	}
+
+	want, err := os.ReadFile(filepath.Join(dir, "export.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "import.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Error("imported registry exports a different configuration")
+	}
+}
+
+// registryRoundTripStep runs step in a child process: "export" registers
+// the extra curve and "import" imports the export written by the first
+// step. Both write the resulting registry export to dir.
+func registryRoundTripStep(t *testing.T, step, dir string, extra asn1.ObjectIdentifier) {
+	if NamedCurveFromOid(extra) != nil {
+		t.Fatal("registry is not fresh")
+	}
+
+	switch step {
+	case "export":
+		if err := ImportRegistry(marshalRegistryExport(t, p256Entry(19, extra))); err != nil {
+			t.Fatal(err)
+		}
+	case "import":
+		data, err := os.ReadFile(filepath.Join(dir, "export.der"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ImportRegistry(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if NamedCurveFromOid(extra) == nil {
+			t.Fatal("extra curve not imported")
+		}
+	default:
+		t.Fatalf("unknown step %q", step)
+	}
+
+	der, err := ExportRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, step+".der"), der, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterCurveDuplicate(t *testing.T) {
+	unused := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 7}
+
+	params := *elliptic.P256().Params()
+	params.Name = "P-256 copy"
+
+	// A new curve under a registered OID.
+	if err := RegisterCurve(&params, oidNamedCurveP256); err == nil {
+		t.Error("duplicate OID accepted")
+	}
+
+	if NamedCurveFromOid(oidNamedCurveP256) != elliptic.P256() {
+		t.Error("duplicate OID replaced the registered curve")
+	}
+
+	// A registered curve under a new OID.
+	if err := RegisterCurve(elliptic.P256(), unused); err == nil {
+		t.Error("duplicate curve accepted")
+	}
+
+	if NamedCurveFromOid(unused) != nil {
+		t.Error("duplicate curve registered")
+	}
+
+	if oid, _ := OidFromNamedCurve(elliptic.P256()); !oid.Equal(oidNamedCurveP256) {
+		t.Errorf("P-256 now maps to %s", oid)
+	}
+
+	// AddNamedCurve records the conflict for VerifyRegistry instead.
+	before := registryErrorCount()
+	AddNamedCurve(elliptic.P256(), unused)
+
+	if after := registryErrorCount(); after != before+1 {
+		t.Errorf("VerifyRegistry reports %d errors, want %d", after, before+1)
+	}
+}
+
+func registryErrorCount() int {
+	err := VerifyRegistry()
+	if err == nil {
+		return 0
+	}
+
+	return len(err.(interface{ Unwrap() []error }).Unwrap())
+}
+
+const registryFreezeEnv = "ECGDSA_REGISTRY_FREEZE"
+
+// TestFreezeRegistry runs in a child process, since freezing cannot be
+// undone.
+func TestFreezeRegistry(t *testing.T) {
+	if os.Getenv(registryFreezeEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFreezeRegistry$")
+		cmd.Env = append(os.Environ(), registryFreezeEnv+"=1")
+
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+
+		return
+	}
+
+	before := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 8}
+	after := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 3, 9}
+
+	curve := func(name string) elliptic.Curve {
+		params := *elliptic.P256().Params()
+		params.Name = name
+		return &params
+	}
+
+	registered := curve("before freeze")
+	if err := RegisterCurve(registered, before); err != nil {
+		t.Fatal(err)
+	}
+
+	FreezeRegistry()
+	errs := registryErrorCount()
+
+	if err := RegisterCurve(curve("after freeze"), after); !errors.Is(err, ErrRegistryFrozen) {
+		t.Errorf("RegisterCurve: got %v, want ErrRegistryFrozen", err)
+	}
+
+	AddNamedCurve(curve("added after freeze"), after)
+
+	if registryErrorCount() != errs+1 || !errors.Is(VerifyRegistry(), ErrRegistryFrozen) {
+		t.Errorf("AddNamedCurve after freeze not recorded: %v", VerifyRegistry())
+	}
+
+	if NamedCurveFromOid(after) != nil {
+		t.Error("curve registered after freeze")
+	}
+
+	// Lookups and the functions that use them keep working, also
+	// concurrently.
+	priv := testKey(t)
+
+	der, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if NamedCurveFromOid(before) != registered || NamedCurveFromOid(oidNamedCurveP256) != elliptic.P256() {
+				t.Error("NamedCurveFromOid failed")
+			}
+
+			if oid, ok := OidFromNamedCurve(registered); !ok || !oid.Equal(before) {
+				t.Error("OidFromNamedCurve failed")
+			}
+
+			if pub, err := ParsePublicKey(der); err != nil || !pub.Equal(&priv.PublicKey) {
+				t.Errorf("ParsePublicKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package ecgdsa
+
+import "math/big"
+
+// Warnings reported by VerifyWithReport. They describe signatures that
+// verify but that a strict verifier may reject in the future.
+const (
+	// WarningNonMinimalLength: a DER length uses the long form, or more
+	// bytes than needed, where DER requires the shortest form.
+	WarningNonMinimalLength = "non-minimal DER length encoding"
+
+	// WarningNonMinimalInteger: r or s has redundant leading zero bytes.
+	WarningNonMinimalInteger = "non-minimal DER integer encoding"
+
+	// WarningShortDigest: the hash value has fewer bits than the curve
+	// order, so the signature is weaker than the curve allows.
+	WarningShortDigest = "hash value shorter than the curve order"
+)
+
+// VerifyWithReport verifies the ASN.1 encoded signature sig of the hash
+// value hash like Verify, but decodes sig leniently and returns the
+// problems that Verify would reject or that weaken the signature as
+// warnings instead. This lets operators find sloppy signers before
+// enforcing strict checks. err is non-nil only when pub is unusable or
+// sig cannot be decoded at all.
+//
+// There is no high-S warning: unlike ECDSA, an EC-GDSA signature (r, s)
+// does not have a second valid form (r, N-s), so the size of s says
+// nothing about malleability and there is no low-S form to ask for.
+func VerifyWithReport(pub *PublicKey, hash, sig []byte) (valid bool, warnings []string, err error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return false, nil, ErrParametersNotSetUp
+	}
+
+	r, s, warnings, err := parseSignatureLax(sig)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if len(hash)*8 < pub.Curve.Params().N.BitLen() {
+		warnings = append(warnings, WarningShortDigest)
+	}
+
+	return verifyDigest(pub, hash, r, s), warnings, nil
+}
+
+// parseSignatureLax decodes a BER SEQUENCE of two INTEGERs, accepting
+// the definite length encodings that DER forbids and reporting them.
+func parseSignatureLax(sig []byte) (r, s *big.Int, warnings []string, err error) {
+	seen := make(map[string]bool)
+	warn := func(w string) {
+		if !seen[w] {
+			seen[w] = true
+			warnings = append(warnings, w)
+		}
+	}
+
+	tag, body, rest, minimal, ok := readLaxTLV(sig)
+	if !ok || tag != 0x30 || len(rest) != 0 {
+		return nil, nil, nil, ErrInvalidASN1
+	}
+
+	if !minimal {
+		warn(WarningNonMinimalLength)
+	}
+
+	ints := make([]*big.Int, 2)
+	for i := range ints {
+		var content []byte
+
+		tag, content, body, minimal, ok = readLaxTLV(body)
+		if !ok || tag != 0x02 || len(content) == 0 || content[0]&0x80 != 0 {
+			return nil, nil, nil, ErrInvalidASN1
+		}
+
+		if !minimal {
+			warn(WarningNonMinimalLength)
+		}
+
+		if len(content) > 1 && content[0] == 0 && content[1]&0x80 == 0 {
+			warn(WarningNonMinimalInteger)
+		}
+
+		ints[i] = new(big.Int).SetBytes(content)
+	}
+
+	if len(body) != 0 {
+		return nil, nil, nil, ErrInvalidASN1
+	}
+
+	return ints[0], ints[1], warnings, nil
+}
+
+// readLaxTLV reads one element with a single byte tag and a definite
+// length, and reports whether the length was minimally encoded.
+func readLaxTLV(data []byte) (tag byte, content, rest []byte, minimal, ok bool) {
+	if len(data) < 2 {
+		return 0, nil, nil, false, false
+	}
+
+	tag = data[0]
+	length := int(data[1])
+	data = data[2:]
+	minimal = true
+
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(data) < n {
+			return 0, nil, nil, false, false
+		}
+
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+
+		data = data[n:]
+		minimal = length >= 0x80 && (length>>(8*(n-1))) != 0
+	}
+
+	if length < 0 || len(data) < length {
+		return 0, nil, nil, false, false
+	}
+
+	return tag, data[:length], data[length:], minimal, true
+}
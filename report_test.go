@@ -0,0 +1,90 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// laxSignature encodes r and s as a SEQUENCE of two INTEGERs, with pad
+// redundant zero bytes in front of r and, if longLength is set, the
+// SEQUENCE length in the long form.
+func laxSignature(r, s *big.Int, pad int, longLength bool) []byte {
+	integer := func(v *big.Int, pad int) []byte {
+		b := v.Bytes()
+		if b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		b = append(make([]byte, pad), b...)
+
+		return append([]byte{0x02, byte(len(b))}, b...)
+	}
+
+	body := append(integer(r, pad), integer(s, 0)...)
+	if longLength {
+		return append([]byte{0x30, 0x81, byte(len(body))}, body...)
+	}
+
+	return append([]byte{0x30, byte(len(body))}, body...)
+}
+
+func TestVerifyWithReport(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	r, s, err := signDigest(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short := sha1.Sum([]byte("message"))
+
+	rShort, sShort, err := signDigest(rand.Reader, priv, short[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		hash     []byte
+		sig      []byte
+		valid    bool
+		strict   bool
+		warnings []string
+	}{
+		{"DER", hash[:], laxSignature(r, s, 0, false), true, true, nil},
+		{"long length", hash[:], laxSignature(r, s, 0, true), true, false,
+			[]string{WarningNonMinimalLength}},
+		{"padded integer", hash[:], laxSignature(r, s, 1, false), true, false,
+			[]string{WarningNonMinimalInteger}},
+		{"both", hash[:], laxSignature(r, s, 2, true), true, false,
+			[]string{WarningNonMinimalLength, WarningNonMinimalInteger}},
+		{"short digest", short[:], laxSignature(rShort, sShort, 0, false), true, true,
+			[]string{WarningShortDigest}},
+		{"wrong signature", hash[:], laxSignature(s, r, 1, false), false, false,
+			[]string{WarningNonMinimalInteger}},
+	} {
+		valid, warnings, err := VerifyWithReport(&priv.PublicKey, tt.hash, tt.sig)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if valid != tt.valid || !reflect.DeepEqual(warnings, tt.warnings) {
+			t.Errorf("%s: got %v, %q, want %v, %q", tt.name, valid, warnings, tt.valid, tt.warnings)
+		}
+
+		// The strict verifier rejects what VerifyWithReport warns about.
+		if VerifyASN1(&priv.PublicKey, tt.hash, tt.sig) != tt.strict {
+			t.Errorf("%s: VerifyASN1 did not return %v", tt.name, tt.strict)
+		}
+	}
+
+	if _, _, err := VerifyWithReport(&priv.PublicKey, hash[:], []byte{0x30, 0x80}); !errors.Is(err, ErrInvalidASN1) {
+		t.Errorf("indefinite length: got %v, want ErrInvalidASN1", err)
+	}
+}
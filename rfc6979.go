@@ -0,0 +1,152 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// SignDeterministic signs the hash value digest with a nonce derived from
+// the private key and digest as in RFC 6979, instead of from a random
+// source, and returns the ASN.1 encoded signature, which Verify accepts.
+// h is the hash of the HMAC used for the derivation and should be the hash
+// that produced digest. The nonce is derived modulo the curve order N,
+// never the field prime P; using P would bias or invalidate the nonces on
+// curves where the two differ in length. It is SignDeterministicWith with
+// the same hash for both roles.
+func SignDeterministic(priv *PrivateKey, digest []byte, h func() hash.Hash) ([]byte, error) {
+	return SignDeterministicWith(priv, digest, h)
+}
+
+// SignDeterministicWith signs the hash value digest with a nonce derived
+// as in RFC 6979, section 3.2, and returns the ASN.1 encoded signature.
+// The HMAC-DRBG that derives the nonce uses hmacHash, which does not have
+// to be the hash that produced digest. The same key, digest and hmacHash
+// always give the same signature.
+//
+// The nonce is sampled in [1, N-1] from the leftmost bits of the DRBG
+// output, N being the order of the curve (not the field prime), and
+// digest is reduced to the bit length of N before entering the DRBG.
+// hmacHash must produce at least SecurityLevel(curve) bits, since a
+// shorter output caps the strength of the nonce.
+func SignDeterministicWith(priv *PrivateKey, digest []byte, hmacHash func() hash.Hash) ([]byte, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if len(digest) == 0 {
+		return nil, errors.New("ecgdsa: empty digest")
+	}
+
+	if hmacHash == nil {
+		return nil, errors.New("ecgdsa: no HMAC hash for nonce derivation")
+	}
+
+	if hmacHash().Size()*8 < SecurityLevel(priv.Curve) {
+		return nil, errors.New("ecgdsa: HMAC hash is too short for the curve")
+	}
+
+	nonce := newRFC6979Nonce(priv.Curve, priv.D, digest, hmacHash)
+
+	r, s, err := signDigestWithNonce(priv, digest, nonce.next)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSignature(r, s)
+}
+
+// rfc6979Nonce is the HMAC-DRBG of RFC 6979, section 3.2.
+type rfc6979Nonce struct {
+	h    func() hash.Hash
+	q    *big.Int
+	qlen int
+	k, v []byte
+}
+
+func newRFC6979Nonce(curve elliptic.Curve, d *big.Int, digest []byte, h func() hash.Hash) *rfc6979Nonce {
+	q := curve.Params().N
+	qlen := q.BitLen()
+	rlen := BitsToBytes(qlen)
+
+	g := &rfc6979Nonce{
+		h:    h,
+		q:    q,
+		qlen: qlen,
+	}
+
+	hlen := h().Size()
+
+	// b, c. V = 0x01 0x01 ... 0x01, K = 0x00 0x00 ... 0x00
+	g.v = make([]byte, hlen)
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	g.k = make([]byte, hlen)
+
+	x := d.FillBytes(make([]byte, rlen))
+
+	// bits2octets(h1) = int2octets(bits2int(h1) mod q)
+	z := g.bits2int(digest)
+	if z.Cmp(q) >= 0 {
+		z.Sub(z, q)
+	}
+	h1 := z.FillBytes(make([]byte, rlen))
+
+	// d, e. K = HMAC_K(V || 0x00 || x || h1), V = HMAC_K(V)
+	g.k = g.mac(g.k, g.v, []byte{0x00}, x, h1)
+	g.v = g.mac(g.k, g.v)
+
+	// f, g. K = HMAC_K(V || 0x01 || x || h1), V = HMAC_K(V)
+	g.k = g.mac(g.k, g.v, []byte{0x01}, x, h1)
+	g.v = g.mac(g.k, g.v)
+
+	return g
+}
+
+func (g *rfc6979Nonce) mac(key []byte, data ...[]byte) []byte {
+	m := hmac.New(g.h, key)
+	for _, d := range data {
+		m.Write(d)
+	}
+
+	return m.Sum(nil)
+}
+
+// bits2int keeps the leftmost qlen bits of b.
+func (g *rfc6979Nonce) bits2int(b []byte) *big.Int {
+	z := new(big.Int).SetBytes(b)
+	if blen := len(b) * 8; blen > g.qlen {
+		z.Rsh(z, uint(blen-g.qlen))
+	}
+
+	return z
+}
+
+// next returns the next candidate nonce in [1, q-1]. Calling it again
+// after a candidate was rejected by the signer continues the DRBG as
+// step h.3 prescribes.
+func (g *rfc6979Nonce) next() (*big.Int, error) {
+	for i := 0; i < MaxRandAttempts; i++ {
+		var t []byte
+		for len(t)*8 < g.qlen {
+			g.v = g.mac(g.k, g.v)
+			t = append(t, g.v...)
+		}
+
+		k := g.bits2int(t)
+
+		// h.3 K = HMAC_K(V || 0x00), V = HMAC_K(V), ready for the next
+		// candidate whether or not this one is used.
+		g.k = g.mac(g.k, g.v, []byte{0x00})
+		g.v = g.mac(g.k, g.v)
+
+		if k.Sign() > 0 && k.Cmp(g.q) < 0 {
+			return k, nil
+		}
+	}
+
+	return nil, ErrRandExhausted
+}
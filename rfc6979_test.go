@@ -0,0 +1,137 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+	"testing"
+)
+
+// TestSignDeterministicP256 uses the private key and message of RFC 6979,
+// A.2.5, P-256 with SHA-256 and "sample". The nonce derivation is that of
+// RFC 6979, so k and r = x(kG) are the published values; s was computed
+// independently as d(kr - h) mod n.
+func TestSignDeterministicP256(t *testing.T) {
+	d, _ := new(big.Int).SetString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721", 16)
+	wantK, _ := new(big.Int).SetString("a6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60", 16)
+	wantR, _ := new(big.Int).SetString("efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716", 16)
+	wantS, _ := new(big.Int).SetString("445d6ca0ad9eca3fa22d30ea61c7f459b43b828bc8398e30a55b4ddb1376a95c", 16)
+
+	priv, err := NewPrivateKeyFromScalar(elliptic.P256(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("sample"))
+
+	k, err := newRFC6979Nonce(priv.Curve, d, digest[:], sha256.New).next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if k.Cmp(wantK) != 0 {
+		t.Errorf("k = %x, want %x", k, wantK)
+	}
+
+	sig, err := SignDeterministic(priv, digest[:], sha256.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Cmp(wantR) != 0 || s.Cmp(wantS) != 0 {
+		t.Errorf("got (%x, %x), want (%x, %x)", r, s, wantR, wantS)
+	}
+
+	if !VerifyASN1(&priv.PublicKey, digest[:], sig) {
+		t.Error("signature does not verify")
+	}
+
+	again, err := SignDeterministic(priv, digest[:], sha256.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(again, sig) {
+		t.Error("second signature differs")
+	}
+}
+
+func TestSignDeterministicWith(t *testing.T) {
+	priv := testKey(t)
+	digest := sha512.Sum384([]byte("message"))
+
+	tests := []struct {
+		name     string
+		hmacHash func() hash.Hash
+	}{
+		{"SHA-224", sha256.New224},
+		{"SHA-256", sha256.New},
+		{"SHA-384", sha512.New384},
+		{"SHA-512", sha512.New},
+	}
+
+	seen := make(map[string]string)
+
+	for _, test := range tests {
+		sig, err := SignDeterministicWith(priv, digest[:], test.hmacHash)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if !VerifyASN1(&priv.PublicKey, digest[:], sig) {
+			t.Errorf("%s: signature does not verify", test.name)
+		}
+
+		again, err := SignDeterministicWith(priv, digest[:], test.hmacHash)
+		if err != nil || !bytes.Equal(again, sig) {
+			t.Errorf("%s: second signature differs", test.name)
+		}
+
+		if other, ok := seen[string(sig)]; ok {
+			t.Errorf("%s: same signature as %s", test.name, other)
+		}
+		seen[string(sig)] = test.name
+	}
+
+	// SignDeterministic is SignDeterministicWith with one hash for both
+	// roles.
+	sig, err := SignDeterministic(priv, digest[:], sha512.New384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seen[string(sig)] != "SHA-384" {
+		t.Error("SignDeterministic differs from SignDeterministicWith")
+	}
+
+	p384, err := GenerateKey(rand.Reader, elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		priv     *PrivateKey
+		digest   []byte
+		hmacHash func() hash.Hash
+	}{
+		{"short HMAC hash", p384, digest[:], sha1.New},
+		{"no HMAC hash", priv, digest[:], nil},
+		{"empty digest", priv, nil, sha256.New},
+		{"no key", &PrivateKey{}, digest[:], sha256.New},
+	} {
+		if _, err := SignDeterministicWith(test.priv, test.digest, test.hmacHash); err == nil {
+			t.Errorf("%s: signed", test.name)
+		}
+	}
+}
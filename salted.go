@@ -0,0 +1,100 @@
+package ecgdsa
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"errors"
+	"io"
+)
+
+// saltSize is the size of the random salt drawn by SignSalted.
+const saltSize = 32
+
+// SaltedSignature is the container produced by SignSalted:
+//
+//	SaltedSignature ::= SEQUENCE {
+//	  salt      OCTET STRING,
+//	  signature OCTET STRING -- ASN.1 EC-GDSA signature
+//	}
+//
+// The signature is over the hash of salt || message.
+type SaltedSignature struct {
+	Salt      []byte
+	Signature []byte
+}
+
+func saltedDigest(h crypto.Hash, salt, message []byte) []byte {
+	d := h.New()
+	d.Write(salt)
+	d.Write(message)
+
+	return d.Sum(nil)
+}
+
+// SignSalted signs message with a fresh 32 byte salt from rand prepended
+// to it before hashing with h, and returns the DER SaltedSignature that
+// carries the salt next to the signature.
+//
+// Because every signature covers a different hash value, two signatures of
+// the same message by the same key share nothing an observer can match,
+// even if a deterministic nonce scheme were used. The salt is sent in the
+// clear, so this does not hide the message: anyone holding a candidate
+// message and the public key can still check whether it was signed.
+func SignSalted(rand io.Reader, priv *PrivateKey, message []byte, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, ErrHashUnavailable
+	}
+
+	if rand == nil {
+		return nil, ErrNilRand
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+
+	r, s, err := signDigest(rand, priv, saltedDigest(h, salt, message))
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := encodeSignature(r, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(SaltedSignature{
+		Salt:      salt,
+		Signature: sig,
+	})
+}
+
+// VerifySalted verifies a SaltedSignature made by SignSalted over message,
+// using the salt it carries. A container that cannot be decoded or whose
+// salt is shorter than 16 bytes is an error.
+func VerifySalted(pub *PublicKey, message, sig []byte, h crypto.Hash) (bool, error) {
+	if !h.Available() {
+		return false, ErrHashUnavailable
+	}
+
+	var salted SaltedSignature
+
+	rest, err := asn1.Unmarshal(sig, &salted)
+	if err != nil {
+		return false, err
+	} else if len(rest) != 0 {
+		return false, errors.New("ecgdsa: trailing data after salted signature")
+	}
+
+	if len(salted.Salt) < 16 {
+		return false, errors.New("ecgdsa: salt too short")
+	}
+
+	r, s, err := parseSignature(salted.Signature)
+	if err != nil {
+		return false, nil
+	}
+
+	return verifyDigest(pub, saltedDigest(h, salted.Salt, message), r, s), nil
+}
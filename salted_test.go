@@ -0,0 +1,71 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestSignSalted(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+	msg := []byte("message")
+
+	var sigs [2]SaltedSignature
+	var ders [2][]byte
+
+	for i := range sigs {
+		der, err := SignSalted(rand.Reader, priv, msg, crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := asn1.Unmarshal(der, &sigs[i]); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(sigs[i].Salt) != saltSize {
+			t.Errorf("salt is %d bytes, want %d", len(sigs[i].Salt), saltSize)
+		}
+
+		ok, err := VerifySalted(pub, msg, der, crypto.SHA256)
+		if err != nil || !ok {
+			t.Fatalf("signature %d: got %v, %v", i, ok, err)
+		}
+
+		if ok, _ := VerifySalted(pub, []byte("other"), der, crypto.SHA256); ok {
+			t.Errorf("signature %d: other message accepted", i)
+		}
+
+		ders[i] = der
+	}
+
+	if bytes.Equal(sigs[0].Salt, sigs[1].Salt) || bytes.Equal(sigs[0].Signature, sigs[1].Signature) {
+		t.Error("two signatures of the same message share a salt or signature")
+	}
+
+	// The signature does not verify with the salt of the other one.
+	swapped, err := asn1.Marshal(SaltedSignature{Salt: sigs[1].Salt, Signature: sigs[0].Signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := VerifySalted(pub, msg, swapped, crypto.SHA256); ok {
+		t.Error("signature verified with another salt")
+	}
+
+	short, err := asn1.Marshal(SaltedSignature{Salt: sigs[0].Salt[:15], Signature: sigs[0].Signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifySalted(pub, msg, short, crypto.SHA256); err == nil {
+		t.Error("short salt accepted")
+	}
+
+	if _, err := VerifySalted(pub, msg, append(ders[0], 0), crypto.SHA256); err == nil {
+		t.Error("trailing data accepted")
+	}
+}
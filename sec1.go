@@ -0,0 +1,94 @@
+package ecgdsa
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const pemECPrivateKeyType = "EC PRIVATE KEY"
+
+// MarshalSEC1PrivateKey returns key as a bare SEC 1 (RFC 5915)
+// ECPrivateKey structure with the named curve OID in its parameters field,
+// as read by "openssl ec".
+func MarshalSEC1PrivateKey(key *PrivateKey) ([]byte, error) {
+	if key == nil || key.Curve == nil || key.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	oid, ok := OidFromNamedCurve(key.Curve)
+	if !ok {
+		return nil, ErrUnsupportedCurve
+	}
+
+	return marshalECPrivateKeyWithOID(key, oid, false)
+}
+
+// ParseSEC1PrivateKey parses a bare SEC 1 ECPrivateKey structure. The
+// curve is taken from the named curve OID. The public key is computed from
+// the scalar; if the structure also carries one, it must match.
+func ParseSEC1PrivateKey(der []byte) (*PrivateKey, error) {
+	if len(der) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	rest, err := asn1.Unmarshal(der, &asn1.RawValue{})
+	if err != nil {
+		return nil, fmt.Errorf("ecgdsa: failed to parse EC private key: %w", err)
+	} else if len(rest) != 0 {
+		return nil, fmt.Errorf("%w after EC private key", ErrTrailingData)
+	}
+
+	return parseECPrivateKey(nil, der, true, nil)
+}
+
+// EncodeSEC1PrivateKeyPEM returns key as SEC 1 in an "EC PRIVATE KEY" PEM
+// block.
+func EncodeSEC1PrivateKeyPEM(key *PrivateKey) ([]byte, error) {
+	der, err := MarshalSEC1PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  pemECPrivateKeyType,
+		Bytes: der,
+	}), nil
+}
+
+// DecodeSEC1PrivateKeyPEM parses a private key from a single
+// "EC PRIVATE KEY" PEM block. Anything but whitespace after the block is
+// an error.
+func DecodeSEC1PrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	der, err := decodeSinglePEM(data, pemECPrivateKeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSEC1PrivateKey(der)
+}
+
+// ParseAnyPrivateKey parses a private key that is either PKCS#8 or a bare
+// SEC 1 ECPrivateKey. PKCS#8 is tried first. If neither parses, the error
+// lists both failures.
+func ParseAnyPrivateKey(der []byte) (*PrivateKey, error) {
+	if len(der) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	key, pkcs8Err := ParsePrivateKey(der)
+	if pkcs8Err == nil {
+		return key, nil
+	}
+
+	key, sec1Err := ParseSEC1PrivateKey(der)
+	if sec1Err == nil {
+		return key, nil
+	}
+
+	return nil, errors.Join(
+		fmt.Errorf("ecgdsa: not a PKCS#8 private key: %w", pkcs8Err),
+		fmt.Errorf("ecgdsa: not a SEC 1 private key: %w", sec1Err),
+	)
+}
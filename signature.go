@@ -0,0 +1,149 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var ErrInvalidSignature = errors.New("ecgdsa: invalid signature")
+
+// Signature is an EC-GDSA signature, encoded in ASN.1 as
+//
+//	ECGDSA-Sig-Value ::= SEQUENCE {
+//	  r INTEGER,
+//	  s INTEGER
+//	}
+type Signature struct {
+	R, S *big.Int
+}
+
+// MarshalSignature returns the DER encoding of sig. R and S must not be
+// negative.
+func MarshalSignature(sig Signature) ([]byte, error) {
+	if sig.R == nil || sig.S == nil || sig.R.Sign() < 0 || sig.S.Sign() < 0 {
+		return nil, ErrInvalidSignature
+	}
+
+	return encodeSignature(sig.R, sig.S)
+}
+
+// ParseSignature decodes a DER encoded signature. Non-minimal encodings,
+// negative integers and trailing data are rejected. The range of R and S
+// is not checked against any curve.
+func ParseSignature(der []byte) (Signature, error) {
+	if len(der) == 0 {
+		return Signature{}, ErrEmptyInput
+	}
+
+	r, s, err := parseSignature(der)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	if r.Sign() < 0 || s.Sign() < 0 {
+		return Signature{}, ErrInvalidASN1
+	}
+
+	return Signature{R: r, S: s}, nil
+}
+
+// rawSignatureSize returns the size of one component of a raw (IEEE P1363,
+// BSI TR-03111 plain) signature, which is the byte length of the curve
+// order.
+func rawSignatureSize(curve elliptic.Curve) int {
+	return BitsToBytes(curve.Params().N.BitLen())
+}
+
+// checkSignatureRange reports whether r and s are both in [1, N-1].
+func checkSignatureRange(curve elliptic.Curve, r, s *big.Int) bool {
+	n := curve.Params().N
+
+	return r.Sign() > 0 && s.Sign() > 0 &&
+		r.Cmp(n) < 0 && s.Cmp(n) < 0
+}
+
+// DERToRaw converts an ASN.1 DER signature to the raw r || s form, each
+// component left-padded to the byte length of the curve order.
+func DERToRaw(curve elliptic.Curve, der []byte) ([]byte, error) {
+	r, s, err := parseSignature(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return RawCodec.Encode(r, s, curve)
+}
+
+// RawToDER converts a raw r || s signature to the ASN.1 DER form.
+func RawToDER(curve elliptic.Curve, raw []byte) ([]byte, error) {
+	r, s, err := RawCodec.Decode(raw, curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSignature(r, s)
+}
+
+// CanonicalizeSignature decodes a signature that may use non-minimal
+// lengths or integers, as parsed by VerifyWithReport, and re-encodes the
+// same (r, s) in minimal DER. r and s must be in [1, N-1] for curve.
+//
+// There is no low-S option: an EC-GDSA signature (r, s) cannot be turned
+// into another valid signature (r, N-s), so s has a single canonical
+// value already.
+func CanonicalizeSignature(der []byte, curve elliptic.Curve) ([]byte, error) {
+	if curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	r, s, _, err := parseSignatureLax(der)
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkSignatureRange(curve, r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	return encodeSignature(r, s)
+}
+
+// SignRaw signs the hash value hash and returns the raw r || s signature,
+// 2*BitsToBytes(N.BitLen()) bytes long with both components left-padded
+// with zeros.
+func SignRaw(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := signDigest(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return RawCodec.Encode(r, s, priv.Curve)
+}
+
+// VerifyRaw verifies a raw r || s signature of the hash value hash. The
+// signature must have exactly the length SignRaw produces for the curve.
+func VerifyRaw(pub *PublicKey, hash, sig []byte) bool {
+	if pub == nil || pub.Curve == nil {
+		return false
+	}
+
+	r, s, err := RawCodec.Decode(sig, pub.Curve)
+	if err != nil {
+		return false
+	}
+
+	return verifyDigest(pub, hash, r, s)
+}
+
+// SignatureToRaw converts an ASN.1 signature to the raw form of SignRaw.
+// It is the same as DERToRaw.
+func SignatureToRaw(curve elliptic.Curve, der []byte) ([]byte, error) {
+	return DERToRaw(curve, der)
+}
+
+// RawToSignature converts a raw signature of SignRaw to the ASN.1 form.
+// It is the same as RawToDER.
+func RawToSignature(curve elliptic.Curve, raw []byte) ([]byte, error) {
+	return RawToDER(curve, raw)
+}
@@ -0,0 +1,364 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestDERRawConversion(t *testing.T) {
+	hash := sha256.Sum256([]byte("message"))
+
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		size  int
+	}{
+		{elliptic.P256(), 32},
+		{elliptic.P384(), 48},
+		{elliptic.P521(), 66},
+	} {
+		name := tt.curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, tt.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		der, err := SignASN1(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := DERToRaw(tt.curve, der)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(raw) != 2*tt.size {
+			t.Errorf("%s: raw signature is %d bytes, want %d", name, len(raw), 2*tt.size)
+		}
+
+		back, err := RawToDER(tt.curve, raw)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !bytes.Equal(back, der) {
+			t.Errorf("%s: DER to raw to DER changed the signature", name)
+		}
+
+		again, err := DERToRaw(tt.curve, back)
+		if err != nil || !bytes.Equal(again, raw) {
+			t.Errorf("%s: raw to DER to raw changed the signature", name)
+		}
+
+		if !VerifyASN1(&priv.PublicKey, hash[:], back) {
+			t.Errorf("%s: converted signature does not verify", name)
+		}
+	}
+}
+
+func TestDERRawConversionRange(t *testing.T) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	raw := func(r, s *big.Int) []byte {
+		b := make([]byte, 64)
+		r.FillBytes(b[:32])
+		s.FillBytes(b[32:])
+		return b
+	}
+
+	der := func(r, s *big.Int) []byte {
+		b, err := encodeSignature(r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(n, one)
+
+	if _, err := RawToDER(curve, raw(one, nMinus1)); err != nil {
+		t.Errorf("r = 1, s = N-1: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		r, s *big.Int
+	}{
+		{"r = 0", new(big.Int), one},
+		{"s = 0", one, new(big.Int)},
+		{"r = N", n, one},
+		{"s = N", one, n},
+	} {
+		if _, err := RawToDER(curve, raw(tt.r, tt.s)); err == nil {
+			t.Errorf("RawToDER: %s accepted", tt.name)
+		}
+
+		if _, err := DERToRaw(curve, der(tt.r, tt.s)); err == nil {
+			t.Errorf("DERToRaw: %s accepted", tt.name)
+		}
+	}
+
+	// A component wider than the order cannot be padded.
+	if _, err := DERToRaw(curve, der(one, new(big.Int).Lsh(one, 300))); err == nil {
+		t.Error("DERToRaw: 300-bit s accepted")
+	}
+
+	for _, b := range [][]byte{nil, make([]byte, 63), make([]byte, 65)} {
+		if _, err := RawToDER(curve, b); err == nil {
+			t.Errorf("RawToDER: %d bytes accepted", len(b))
+		}
+	}
+
+	if _, err := DERToRaw(curve, []byte{0x30, 0x03, 0x02, 0x01, 0x01}); err == nil {
+		t.Error("DERToRaw: truncated DER accepted")
+	}
+}
+
+// laxTLV encodes tag and content, with the length in long form taking
+// lengthBytes bytes when lengthBytes is not zero.
+func laxTLV(tag byte, content []byte, lengthBytes int) []byte {
+	out := []byte{tag}
+
+	if lengthBytes == 0 {
+		out = append(out, byte(len(content)))
+	} else {
+		out = append(out, 0x80|byte(lengthBytes))
+		for i := lengthBytes - 1; i >= 0; i-- {
+			out = append(out, byte(len(content)>>(8*i)))
+		}
+	}
+
+	return append(out, content...)
+}
+
+func TestCanonicalizeSignature(t *testing.T) {
+	priv := testKey(t)
+	digest := sha256.Sum256([]byte("message"))
+
+	sig, err := SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lax re-encodes (r, s) with padding zero bytes before each integer
+	// and lengthBytes long-form lengths.
+	lax := func(rPad, sPad, lengthBytes int) []byte {
+		rb := append(make([]byte, rPad+1), r.Bytes()...)
+		sb := append(make([]byte, sPad+1), s.Bytes()...)
+
+		body := append(laxTLV(0x02, rb, lengthBytes), laxTLV(0x02, sb, lengthBytes)...)
+		return laxTLV(0x30, body, lengthBytes)
+	}
+
+	for _, tt := range []struct {
+		name string
+		in   []byte
+	}{
+		{"minimal", sig},
+		{"long lengths", lax(0, 0, 1)},
+		{"two byte lengths", lax(0, 0, 2)},
+		{"padded r", lax(2, 0, 0)},
+		{"padded s", lax(0, 3, 0)},
+		{"padded and long", lax(1, 1, 2)},
+	} {
+		out, err := CanonicalizeSignature(tt.in, priv.Curve)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if !bytes.Equal(out, sig) {
+			t.Errorf("%s: got %x, want %x", tt.name, out, sig)
+		}
+
+		gotR, gotS, err := parseSignature(out)
+		if err != nil || gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 {
+			t.Errorf("%s: components changed", tt.name)
+		}
+
+		if !VerifyASN1(&priv.PublicKey, digest[:], out) {
+			t.Errorf("%s: output does not verify", tt.name)
+		}
+	}
+
+	n := priv.Curve.Params().N
+	outOfRange, err := encodeSignature(r, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		in    []byte
+		curve elliptic.Curve
+	}{
+		{"empty", nil, priv.Curve},
+		{"trailing data", append(append([]byte{}, sig...), 0), priv.Curve},
+		{"not a sequence", laxTLV(0x31, sig[2:], 0), priv.Curve},
+		{"negative r", laxTLV(0x30, append(laxTLV(0x02, []byte{0x80}, 0), laxTLV(0x02, []byte{1}, 0)...), 0), priv.Curve},
+		{"zero r", laxTLV(0x30, append(laxTLV(0x02, []byte{0}, 0), laxTLV(0x02, []byte{1}, 0)...), 0), priv.Curve},
+		{"s = N", outOfRange, priv.Curve},
+		{"no curve", sig, nil},
+	} {
+		if _, err := CanonicalizeSignature(tt.in, tt.curve); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
+
+func TestSignRaw(t *testing.T) {
+	hash := sha256.Sum256([]byte("message"))
+	other := sha256.Sum256([]byte("other"))
+
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		size  int
+	}{
+		{elliptic.P224(), 28},
+		{elliptic.P256(), 32},
+		{elliptic.P384(), 48},
+		{elliptic.P521(), 66},
+	} {
+		name := tt.curve.Params().Name
+
+		priv, err := GenerateKey(rand.Reader, tt.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub := &priv.PublicKey
+
+		raw, err := SignRaw(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(raw) != 2*tt.size {
+			t.Errorf("%s: signature is %d bytes, want %d", name, len(raw), 2*tt.size)
+		}
+
+		if !VerifyRaw(pub, hash[:], raw) {
+			t.Errorf("%s: signature rejected", name)
+		}
+
+		if VerifyRaw(pub, other[:], raw) {
+			t.Errorf("%s: other hash accepted", name)
+		}
+
+		der, err := RawToSignature(tt.curve, raw)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !VerifyASN1(pub, hash[:], der) {
+			t.Errorf("%s: converted signature rejected", name)
+		}
+
+		back, err := SignatureToRaw(tt.curve, der)
+		if err != nil || !bytes.Equal(back, raw) {
+			t.Errorf("%s: round trip changed the signature", name)
+		}
+
+		for _, bad := range []struct {
+			name string
+			sig  []byte
+		}{
+			{"empty", nil},
+			{"truncated", raw[:len(raw)-1]},
+			{"extra zero", append([]byte{0}, raw...)},
+			{"trailing zero", append(append([]byte{}, raw...), 0)},
+			{"DER", der},
+		} {
+			if VerifyRaw(pub, hash[:], bad.sig) {
+				t.Errorf("%s: %s accepted", name, bad.name)
+			}
+		}
+	}
+
+	if VerifyRaw(nil, hash[:], make([]byte, 64)) {
+		t.Error("nil key accepted")
+	}
+
+	if _, err := SignRaw(rand.Reader, &PrivateKey{}, hash[:]); err == nil {
+		t.Error("empty key signed")
+	}
+}
+
+func TestMarshalParseSignature(t *testing.T) {
+	priv := testKey(t)
+	hash := sha256.Sum256([]byte("message"))
+
+	der, err := SignASN1(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := ParseSignature(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := MarshalSignature(sig)
+	if err != nil || !bytes.Equal(again, der) {
+		t.Error("round trip changed the signature")
+	}
+
+	// The range is not checked against a curve.
+	for _, v := range []*big.Int{big.NewInt(0), big.NewInt(0x80), new(big.Int).Lsh(big.NewInt(1), 600)} {
+		der, err := MarshalSignature(Signature{R: v, S: v})
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+
+		sig, err := ParseSignature(der)
+		if err != nil || sig.R.Cmp(v) != 0 || sig.S.Cmp(v) != 0 {
+			t.Errorf("%v: got %v, %v", v, sig, err)
+		}
+	}
+
+	one := laxTLV(0x02, []byte{1}, 0)
+
+	for _, tt := range []struct {
+		name string
+		der  []byte
+	}{
+		{"empty", nil},
+		{"negative r", laxTLV(0x30, append(laxTLV(0x02, []byte{0x80}, 0), one...), 0)},
+		{"negative s", laxTLV(0x30, append(append([]byte{}, one...), laxTLV(0x02, []byte{0xff, 0x01}, 0)...), 0)},
+		{"padded r", laxTLV(0x30, append(laxTLV(0x02, []byte{0, 1}, 0), one...), 0)},
+		{"long-form length", laxTLV(0x30, append(append([]byte{}, one...), one...), 1)},
+		{"trailing data", append(append([]byte{}, der...), 0)},
+		{"one integer", laxTLV(0x30, one, 0)},
+		{"three integers", laxTLV(0x30, bytes.Repeat(one, 3), 0)},
+		{"not a sequence", laxTLV(0x31, append(append([]byte{}, one...), one...), 0)},
+	} {
+		if _, err := ParseSignature(tt.der); err == nil {
+			t.Errorf("%s: parsed", tt.name)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		sig  Signature
+	}{
+		{"nil R", Signature{S: big.NewInt(1)}},
+		{"nil S", Signature{R: big.NewInt(1)}},
+		{"negative S", Signature{R: big.NewInt(1), S: big.NewInt(-1)}},
+	} {
+		if _, err := MarshalSignature(tt.sig); err == nil {
+			t.Errorf("%s: marshalled", tt.name)
+		}
+	}
+}
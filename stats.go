@@ -0,0 +1,74 @@
+package ecgdsa
+
+import "sync/atomic"
+
+// Stats counts verification outcomes. Its counters are updated atomically,
+// so one Stats can be shared by concurrent verifiers. The zero value is
+// ready to use.
+type Stats struct {
+	total     atomic.Uint64
+	success   atomic.Uint64
+	failure   atomic.Uint64
+	malformed atomic.Uint64
+}
+
+// StatsSnapshot is a point in time copy of the counters of a Stats.
+type StatsSnapshot struct {
+	// Total is the number of verifications.
+	Total uint64
+
+	// Success is the number of valid signatures.
+	Success uint64
+
+	// Failure is the number of well-formed signatures that did not verify.
+	Failure uint64
+
+	// Malformed is the number of signatures rejected because they could
+	// not be decoded.
+	Malformed uint64
+}
+
+// Snapshot returns a copy of the counters. The counters are read one by
+// one, so under concurrent use they may be off by the verifications in
+// flight.
+func (st *Stats) Snapshot() StatsSnapshot {
+	return StatsSnapshot{
+		Total:     st.total.Load(),
+		Success:   st.success.Load(),
+		Failure:   st.failure.Load(),
+		Malformed: st.malformed.Load(),
+	}
+}
+
+// VerifierWithStats verifies ASN.1 encoded signatures with a public key and
+// records the outcomes in Stats. Plain Verify calls are not counted, so
+// code that does not use a VerifierWithStats pays nothing for it.
+type VerifierWithStats struct {
+	Pub   *PublicKey
+	Stats *Stats
+}
+
+// NewVerifierWithStats returns a verifier for pub that records into st.
+func NewVerifierWithStats(pub *PublicKey, st *Stats) *VerifierWithStats {
+	return &VerifierWithStats{Pub: pub, Stats: st}
+}
+
+// Verify is like the package-level Verify and records its outcome.
+func (v *VerifierWithStats) Verify(h Hasher, data, sig []byte) bool {
+	v.Stats.total.Add(1)
+
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		v.Stats.malformed.Add(1)
+		return false
+	}
+
+	if !VerifyWithRS(v.Pub, h, data, r, s) {
+		v.Stats.failure.Add(1)
+		return false
+	}
+
+	v.Stats.success.Add(1)
+
+	return true
+}
@@ -0,0 +1,64 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"testing"
+)
+
+func TestVerifierWithStats(t *testing.T) {
+	const (
+		workers = 8
+		rounds  = 25
+	)
+
+	priv := testKey(t)
+	msg := []byte("message")
+
+	sig, err := Sign(rand.Reader, priv, sha256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var st Stats
+	v := NewVerifierWithStats(&priv.PublicKey, &st)
+
+	// Each round verifies a valid signature, one for another message and
+	// a malformed one.
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < rounds; i++ {
+				if !v.Verify(sha256.New, msg, sig) {
+					t.Error("valid signature rejected")
+				}
+
+				if v.Verify(sha256.New, []byte("other"), sig) {
+					t.Error("signature of another message accepted")
+				}
+
+				if v.Verify(sha256.New, msg, []byte{0x30, 0x00}) {
+					t.Error("malformed signature accepted")
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	want := StatsSnapshot{
+		Total:     3 * workers * rounds,
+		Success:   workers * rounds,
+		Failure:   workers * rounds,
+		Malformed: workers * rounds,
+	}
+
+	if got := st.Snapshot(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package ecgdsa
+
+import (
+	"hash"
+	"io"
+)
+
+// SigningStream hashes a message written to it in pieces and signs the
+// result, so that large inputs never have to be held in memory. The
+// signature is the same one Sign would produce for the whole message.
+type SigningStream struct {
+	priv   *PrivateKey
+	hasher Hasher
+	h      hash.Hash
+}
+
+// NewSigningStream returns a SigningStream that hashes with h and signs
+// with priv.
+func NewSigningStream(priv *PrivateKey, h Hasher) *SigningStream {
+	return &SigningStream{priv: priv, hasher: h, h: h()}
+}
+
+// Write adds p to the message. It never returns an error.
+func (st *SigningStream) Write(p []byte) (int, error) {
+	return st.h.Write(p)
+}
+
+// Finish signs the message written so far and returns the ASN.1 encoded
+// signature. More data may be written afterwards; a later Finish signs
+// the longer message.
+func (st *SigningStream) Finish(rand io.Reader) ([]byte, error) {
+	if st.priv == nil || st.priv.Curve == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if err := fipsCheckHash(st.hasher, st.priv.Curve); err != nil {
+		return nil, err
+	}
+
+	r, s, err := signDigest(rand, st.priv, st.h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSignature(r, s)
+}
+
+// VerifyingStream hashes a message written to it in pieces and checks an
+// ASN.1 encoded signature of it, as Verify does for a whole message.
+type VerifyingStream struct {
+	pub    *PublicKey
+	sig    []byte
+	hasher Hasher
+	h      hash.Hash
+}
+
+// NewVerifyingStream returns a VerifyingStream that hashes with h and
+// checks sig against pub.
+func NewVerifyingStream(pub *PublicKey, h Hasher, sig []byte) *VerifyingStream {
+	return &VerifyingStream{pub: pub, sig: sig, hasher: h, h: h()}
+}
+
+// Write adds p to the message. It never returns an error.
+func (st *VerifyingStream) Write(p []byte) (int, error) {
+	return st.h.Write(p)
+}
+
+// Verify reports whether the signature is valid for the message written
+// so far.
+func (st *VerifyingStream) Verify() bool {
+	if st.pub == nil || fipsCheckHash(st.hasher, st.pub.Curve) != nil {
+		return false
+	}
+
+	r, s, err := parseSignature(st.sig)
+	if err != nil {
+		return false
+	}
+
+	return verifyDigest(st.pub, st.h.Sum(nil), r, s)
+}
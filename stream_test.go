@@ -0,0 +1,87 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+// writeChunks writes msg to w in pieces of 1, 2, 3, ... bytes.
+func writeChunks(t *testing.T, w io.Writer, msg []byte) {
+	t.Helper()
+
+	for size := 1; len(msg) > 0; size++ {
+		n := size
+		if n > len(msg) {
+			n = len(msg)
+		}
+
+		if _, err := w.Write(msg[:n]); err != nil {
+			t.Fatal(err)
+		}
+
+		msg = msg[n:]
+	}
+}
+
+func TestSigningStream(t *testing.T) {
+	priv := testKey(t)
+	pub := &priv.PublicKey
+
+	msg := make([]byte, 100000)
+	if _, err := rand.Read(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	st := NewSigningStream(priv, sha256.New)
+	writeChunks(t, st, msg)
+
+	sig, err := st.Finish(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Verify(pub, sha256.New, msg, sig) {
+		t.Error("stream signature rejected by Verify")
+	}
+
+	vst := NewVerifyingStream(pub, sha256.New, sig)
+	writeChunks(t, vst, msg)
+
+	if !vst.Verify() {
+		t.Error("stream signature rejected by VerifyingStream")
+	}
+
+	// A signature made by Sign verifies with a stream as well.
+	whole, err := Sign(rand.Reader, priv, sha256.New, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vst = NewVerifyingStream(pub, sha256.New, whole)
+	writeChunks(t, vst, msg)
+
+	if !vst.Verify() {
+		t.Error("Sign signature rejected by VerifyingStream")
+	}
+
+	// Writing after Finish extends the message.
+	st.Write([]byte("more"))
+
+	longer, err := st.Finish(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Verify(pub, sha256.New, append(msg, "more"...), longer) || Verify(pub, sha256.New, msg, longer) {
+		t.Error("second Finish does not sign the extended message")
+	}
+
+	vst = NewVerifyingStream(pub, sha256.New, sig)
+	writeChunks(t, vst, msg[:len(msg)-1])
+
+	if vst.Verify() {
+		t.Error("truncated message accepted")
+	}
+}
@@ -0,0 +1,96 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// shortSignatureKey returns a P-256 key and a signature (r, s) of msg with
+// SHA-256 where both r and s are shorter than 32 bytes. The nonce is
+// searched for a short r, s is picked short and the key solved from
+// s = d(kr - h).
+func shortSignatureKey(t *testing.T, msg []byte) (*PrivateKey, *big.Int, *big.Int) {
+	t.Helper()
+
+	curve := elliptic.P256()
+	n := curve.Params().N
+	limit := new(big.Int).Lsh(big.NewInt(1), 248)
+
+	var k, r *big.Int
+	for r == nil || r.Sign() == 0 || r.Cmp(limit) >= 0 {
+		var err error
+		if k, err = rand.Int(rand.Reader, n); err != nil {
+			t.Fatal(err)
+		}
+
+		x, _ := curve.ScalarBaseMult(k.Bytes())
+		r = x.Mod(x, n)
+	}
+
+	s, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(msg)
+
+	t0 := new(big.Int).Mul(k, r)
+	t0.Sub(t0, hashToInt(digest[:], n))
+	t0.Mod(t0, n)
+
+	d := new(big.Int).Mul(s, t0.ModInverse(t0, n))
+	d.Mod(d, n)
+
+	priv, err := NewPrivateKeyFromScalar(curve, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return priv, r, s
+}
+
+func TestVerifyBytesTolerantRaw(t *testing.T) {
+	msg := []byte("message")
+	priv, r, s := shortSignatureKey(t, msg)
+	pub := &priv.PublicKey
+
+	if !VerifyWithRS(pub, sha256.New, msg, r, s) {
+		t.Fatal("constructed signature does not verify")
+	}
+
+	full := make([]byte, 64)
+	r.FillBytes(full[:32])
+	s.FillBytes(full[32:])
+
+	// Both components lose their leading zero byte.
+	short := append(append([]byte{}, full[1:32]...), full[33:]...)
+
+	tolerant := &VerifyOpts{TolerantRaw: true}
+
+	for _, tt := range []struct {
+		name string
+		sig  []byte
+		opts *VerifyOpts
+		want bool
+	}{
+		{"full strict", full, nil, true},
+		{"full tolerant", full, tolerant, true},
+		{"short strict", short, nil, false},
+		{"short tolerant", short, tolerant, true},
+		{"odd length tolerant", full[1:], tolerant, false},
+		{"too long tolerant", append(append([]byte{}, full...), 0, 0), tolerant, false},
+		{"empty tolerant", nil, tolerant, false},
+		{"half tolerant", full[:32], tolerant, false},
+	} {
+		if got := VerifyBytesWithOpts(pub, sha256.New, msg, tt.sig, tt.opts); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if VerifyBytes(pub, sha256.New, msg, short) {
+		t.Error("VerifyBytes accepted a short signature")
+	}
+}
@@ -0,0 +1,91 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+// twinCurves pairs each brainpool r1 curve with its t1 twist. The two
+// curves of a pair are isomorphic and share the order N, and the t1
+// generator is the image of the r1 generator, so a private key gives
+// corresponding public points on both.
+var twinCurves = [][2]elliptic.Curve{
+	{brainpool.P160r1(), brainpool.P160t1()},
+	{brainpool.P192r1(), brainpool.P192t1()},
+	{brainpool.P224r1(), brainpool.P224t1()},
+	{brainpool.P256r1(), brainpool.P256t1()},
+	{brainpool.P320r1(), brainpool.P320t1()},
+	{brainpool.P384r1(), brainpool.P384t1()},
+	{brainpool.P512r1(), brainpool.P512t1()},
+}
+
+// twinCurve returns the isomorphic twin of curve, or nil.
+func twinCurve(curve elliptic.Curve) elliptic.Curve {
+	for _, pair := range twinCurves {
+		switch curve {
+		case pair[0]:
+			return pair[1]
+		case pair[1]:
+			return pair[0]
+		}
+	}
+
+	return nil
+}
+
+// mapToTwin maps the point (x, y) from curve to its twin by the
+// isomorphism (x, y) -> (Z²x, Z³y) of RFC 5639, section 3. Z² and Z³ are
+// recovered from the two generators, so no constants are needed.
+func mapToTwin(from, to elliptic.Curve, x, y *big.Int) (tx, ty *big.Int) {
+	fp, tp := from.Params(), to.Params()
+	p := fp.P
+
+	z2 := new(big.Int).ModInverse(fp.Gx, p)
+	z3 := new(big.Int).ModInverse(fp.Gy, p)
+	if z2 == nil || z3 == nil {
+		return nil, nil
+	}
+
+	z2.Mul(z2, tp.Gx)
+	z3.Mul(z3, tp.Gy)
+
+	tx = z2.Mul(z2, x)
+	tx.Mod(tx, p)
+
+	ty = z3.Mul(z3, y)
+	ty.Mod(ty, p)
+
+	if !to.IsOnCurve(tx, ty) {
+		return nil, nil
+	}
+
+	return tx, ty
+}
+
+// twinCurveDiagnostic reports whether the signature (r, s), which failed
+// on the curve of pub, verifies for the same key on the twin curve.
+func twinCurveDiagnostic(pub *PublicKey, h Hasher, data []byte, r, s *big.Int) error {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return nil
+	}
+
+	twin := twinCurve(pub.Curve)
+	if twin == nil {
+		return nil
+	}
+
+	x, y := mapToTwin(pub.Curve, twin, pub.X, pub.Y)
+	if x == nil {
+		return nil
+	}
+
+	if !VerifyWithRS(&PublicKey{Curve: twin, X: x, Y: y}, h, data, r, s) {
+		return nil
+	}
+
+	return fmt.Errorf("ecgdsa: signature does not verify on %s but would on its twin %s; the signer likely used the other curve",
+		pub.Curve.Params().Name, twin.Params().Name)
+}
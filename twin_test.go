@@ -0,0 +1,78 @@
+package ecgdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/pedroalbanese/brainpool"
+)
+
+func TestTwinCurveDiagnostic(t *testing.T) {
+	r1, t1 := brainpool.P256r1(), brainpool.P256t1()
+	data := []byte("message")
+
+	onR1, err := GenerateKey(rand.Reader, r1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same scalar on the twist gives the image of the r1 public key.
+	onT1, err := NewPrivateKeyFromScalar(t1, onR1.D)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x, y := mapToTwin(r1, t1, onR1.X, onR1.Y); x == nil || x.Cmp(onT1.X) != 0 || y.Cmp(onT1.Y) != 0 {
+		t.Fatal("mapToTwin does not map the public key to its twin")
+	}
+
+	opts := &VerifyOpts{TwinCurveDiagnostics: true}
+
+	for _, tt := range []struct {
+		name        string
+		signer, pub *PrivateKey
+	}{
+		{"t1 signature, r1 key", onT1, onR1},
+		{"r1 signature, t1 key", onR1, onT1},
+	} {
+		sig, err := Sign(rand.Reader, tt.signer, sha256.New, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ok, err := VerifyWithOpts(&tt.pub.PublicKey, sha256.New, data, sig, opts)
+		if ok {
+			t.Errorf("%s: accepted", tt.name)
+		}
+
+		if err == nil {
+			t.Errorf("%s: no twin curve diagnostic", tt.name)
+		}
+
+		// Without the option the result is the same and nothing is reported.
+		if ok, err := VerifyWithOpts(&tt.pub.PublicKey, sha256.New, data, sig, nil); ok || err != nil {
+			t.Errorf("%s: without diagnostics got %v, %v", tt.name, ok, err)
+		}
+	}
+
+	// A signature that is wrong on both curves gets no diagnostic.
+	sig, err := Sign(rand.Reader, onT1, sha256.New, []byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := VerifyWithOpts(&onR1.PublicKey, sha256.New, data, sig, opts); ok || err != nil {
+		t.Errorf("unrelated signature: got %v, %v", ok, err)
+	}
+
+	// A valid signature verifies without a diagnostic.
+	sig, err = Sign(rand.Reader, onR1, sha256.New, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := VerifyWithOpts(&onR1.PublicKey, sha256.New, data, sig, opts); !ok || err != nil {
+		t.Errorf("valid signature: got %v, %v", ok, err)
+	}
+}
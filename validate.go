@@ -0,0 +1,176 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Errors returned, possibly wrapped, by PublicKey.Validate and
+// PrivateKey.Validate.
+var (
+	ErrPointAtInfinity    = errors.New("ecgdsa: public key is the point at infinity")
+	ErrPointNotOnCurve    = errors.New("ecgdsa: public key is not on the curve")
+	ErrPointNotInSubgroup = errors.New("ecgdsa: public key is not in the subgroup of order N")
+)
+
+// Validate checks that pub is a usable public key: its coordinates are
+// reduced field elements, it is not the point at infinity, it is on the
+// curve and, for curves with a cofactor greater than one, [N]P is the
+// point at infinity. This guards against invalid-curve and small-subgroup
+// attacks with keys from untrusted sources.
+func (pub *PublicKey) Validate() error {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return ErrParametersNotSetUp
+	}
+
+	params := pub.Curve.Params()
+
+	if pub.X.Sign() == 0 && pub.Y.Sign() == 0 {
+		return ErrPointAtInfinity
+	}
+
+	if pub.X.Sign() < 0 || pub.Y.Sign() < 0 ||
+		pub.X.Cmp(params.P) >= 0 || pub.Y.Cmp(params.P) >= 0 ||
+		!pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return ErrPointNotOnCurve
+	}
+
+	if estimateCofactor(params).Cmp(big.NewInt(1)) > 0 {
+		x, y := pub.Curve.ScalarMult(pub.X, pub.Y, params.N.Bytes())
+		if x.Sign() != 0 || y.Sign() != 0 {
+			return ErrPointNotInSubgroup
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the public key as PublicKey.Validate does, that D is in
+// [1, N-1] and that the public key is the one computed from D.
+func (priv *PrivateKey) Validate() error {
+	if priv == nil || priv.D == nil {
+		return ErrParametersNotSetUp
+	}
+
+	if err := priv.PublicKey.Validate(); err != nil {
+		return err
+	}
+
+	if priv.D.Sign() <= 0 || priv.D.Cmp(priv.Curve.Params().N) >= 0 {
+		return ErrInvalidPrivateKeyValue
+	}
+
+	if x, y := XY(priv.D, priv.Curve); x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+		return ErrKeyMismatch
+	}
+
+	return nil
+}
+
+// ValidatePKCS8 checks that der is a well-formed PKCS#8 EC-GDSA private
+// key without returning the key. It reports every problem it finds,
+// joined with errors.Join, or nil if there is none.
+//
+// The checks are: the outer structure decodes without trailing data, its
+// version is 0 or 1, the algorithm is EC-GDSA, the parameters name a
+// supported curve, and the inner EC private key has version 1, a curve
+// that agrees with the outer one, a private key octet string no longer
+// than the curve order, a scalar in [1, N-1] and, if present, a public key
+// that matches the scalar.
+func ValidatePKCS8(der []byte) error {
+	var privKey pkcs8
+
+	rest, err := asn1.Unmarshal(der, &privKey)
+	if err != nil {
+		return fmt.Errorf("ecgdsa: invalid PKCS#8 structure: %w", err)
+	}
+
+	var errs []error
+
+	if len(rest) != 0 {
+		errs = append(errs, errors.New("ecgdsa: trailing data after PKCS#8 structure"))
+	}
+
+	if privKey.Version != 0 && privKey.Version != 1 {
+		errs = append(errs, fmt.Errorf("ecgdsa: unknown PKCS#8 version %d", privKey.Version))
+	}
+
+	if !privKey.Algo.Algorithm.Equal(oidPublicKeyECGDSA) {
+		errs = append(errs, fmt.Errorf("ecgdsa: unknown private key algorithm %s", privKey.Algo.Algorithm))
+	}
+
+	var curve elliptic.Curve
+	if params := privKey.Algo.Parameters.FullBytes; len(params) > 0 {
+		curve, err = parseCurveParameters(params, nil)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, validateECPrivateKey(curve, privKey.PrivateKey)...)
+
+	return errors.Join(errs...)
+}
+
+// validateECPrivateKey checks the inner EC private key structure against
+// the curve from the outer structure, which may be nil.
+func validateECPrivateKey(curve elliptic.Curve, der []byte) []error {
+	var ecKey ecPrivateKey
+
+	rest, err := asn1.Unmarshal(der, &ecKey)
+	if err != nil {
+		return []error{fmt.Errorf("ecgdsa: invalid EC private key structure: %w", err)}
+	}
+
+	var errs []error
+
+	if len(rest) != 0 {
+		errs = append(errs, errors.New("ecgdsa: trailing data after EC private key structure"))
+	}
+
+	if ecKey.Version != ecPrivKeyVersion {
+		errs = append(errs, fmt.Errorf("ecgdsa: unknown EC private key version %d", ecKey.Version))
+	}
+
+	if len(ecKey.Parameters.Bytes) > 0 {
+		inner, err := parseCurveParameters(ecKey.Parameters.Bytes, nil)
+
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("ecgdsa: unsupported EC private key curve: %w", err))
+		case curve != nil && !sameCurve(inner, curve):
+			errs = append(errs, errors.New("ecgdsa: EC private key curve does not match the PKCS#8 curve"))
+		case curve == nil:
+			curve = inner
+		}
+	}
+
+	if curve == nil {
+		return append(errs, errors.New("ecgdsa: no known curve for the private key"))
+	}
+
+	n := curve.Params().N
+
+	if size := BitsToBytes(n.BitLen()); len(ecKey.PrivateKey) > size {
+		errs = append(errs, fmt.Errorf("ecgdsa: private key is %d bytes, want at most %d", len(ecKey.PrivateKey), size))
+	}
+
+	d := new(big.Int).SetBytes(ecKey.PrivateKey)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return append(errs, errors.New("ecgdsa: private key value out of range"))
+	}
+
+	if len(ecKey.PublicKey.Bytes) > 0 {
+		x, y := unmarshalPoint(curve, ecKey.PublicKey.Bytes)
+		if x == nil {
+			errs = append(errs, errors.New("ecgdsa: invalid embedded public key"))
+		} else if px, py := XY(d, curve); x.Cmp(px) != 0 || y.Cmp(py) != 0 {
+			errs = append(errs, ErrKeyMismatch)
+		}
+	}
+
+	return errs
+}
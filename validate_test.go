@@ -0,0 +1,184 @@
+package ecgdsa
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// toyCurve is y² = x³ - 3x + 66 over GF(10007), which has 10012 = 4 · 2503
+// points. G generates the subgroup of order 2503 and (1, 8) has order
+// 10012.
+var toyCurve = &elliptic.CurveParams{
+	Name:    "toy",
+	P:       big.NewInt(10007),
+	N:       big.NewInt(2503),
+	B:       big.NewInt(66),
+	Gx:      big.NewInt(1607),
+	Gy:      big.NewInt(6542),
+	BitSize: 14,
+}
+
+func TestPublicKeyValidate(t *testing.T) {
+	priv := testKey(t)
+	p := priv.Params().P
+
+	for _, tt := range []struct {
+		name string
+		pub  *PublicKey
+		err  error
+	}{
+		{"valid", &priv.PublicKey, nil},
+		{"valid cofactor 4", &PublicKey{Curve: toyCurve, X: toyCurve.Gx, Y: toyCurve.Gy}, nil},
+		{"nil", nil, ErrParametersNotSetUp},
+		{"no point", &PublicKey{Curve: priv.Curve}, ErrParametersNotSetUp},
+		{"infinity", &PublicKey{Curve: priv.Curve, X: new(big.Int), Y: new(big.Int)}, ErrPointAtInfinity},
+		{"off curve", &PublicKey{Curve: priv.Curve, X: priv.X, Y: new(big.Int).Add(priv.Y, big.NewInt(1))}, ErrPointNotOnCurve},
+		{"x not reduced", &PublicKey{Curve: priv.Curve, X: new(big.Int).Add(priv.X, p), Y: priv.Y}, ErrPointNotOnCurve},
+		{"negative y", &PublicKey{Curve: priv.Curve, X: priv.X, Y: new(big.Int).Sub(priv.Y, p)}, ErrPointNotOnCurve},
+		{"small subgroup", &PublicKey{Curve: toyCurve, X: big.NewInt(1), Y: big.NewInt(8)}, ErrPointNotInSubgroup},
+	} {
+		if err := tt.pub.Validate(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestPrivateKeyValidate(t *testing.T) {
+	priv := testKey(t)
+	other := testKey(t)
+	n := priv.Params().N
+
+	withD := func(d *big.Int) *PrivateKey {
+		return &PrivateKey{PublicKey: priv.PublicKey, D: d}
+	}
+
+	for _, tt := range []struct {
+		name string
+		priv *PrivateKey
+		err  error
+	}{
+		{"valid", priv, nil},
+		{"nil", nil, ErrParametersNotSetUp},
+		{"no D", withD(nil), ErrParametersNotSetUp},
+		{"zero D", withD(new(big.Int)), ErrInvalidPrivateKeyValue},
+		{"negative D", withD(big.NewInt(-1)), ErrInvalidPrivateKeyValue},
+		{"D = N", withD(new(big.Int).Set(n)), ErrInvalidPrivateKeyValue},
+		{"D + N", withD(new(big.Int).Add(priv.D, n)), ErrInvalidPrivateKeyValue},
+		{"mismatch", withD(other.D), ErrKeyMismatch},
+		{"bad public key", &PrivateKey{PublicKey: PublicKey{Curve: priv.Curve, X: new(big.Int), Y: new(big.Int)}, D: priv.D}, ErrPointAtInfinity},
+	} {
+		if err := tt.priv.Validate(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestValidatePKCS8(t *testing.T) {
+	priv := testKey(t)
+
+	der, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// build returns der with the outer and inner structures edited.
+	build := func(edit func(outer *pkcs8, inner *ecPrivateKey)) []byte {
+		var outer pkcs8
+		if _, err := asn1.Unmarshal(der, &outer); err != nil {
+			t.Fatal(err)
+		}
+
+		var inner ecPrivateKey
+		if _, err := asn1.Unmarshal(outer.PrivateKey, &inner); err != nil {
+			t.Fatal(err)
+		}
+
+		edit(&outer, &inner)
+
+		b, err := asn1.Marshal(inner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outer.PrivateKey = b
+
+		if b, err = asn1.Marshal(outer); err != nil {
+			t.Fatal(err)
+		}
+
+		return b
+	}
+
+	p384, err := asn1.Marshal(oidNamedCurveP384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherDER, err := MarshalSEC1PrivateKey(testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var other ecPrivateKey
+	if _, err := asn1.Unmarshal(otherDER, &other); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		der  []byte
+		errs int
+		is   error
+	}{
+		{"valid", der, 0, nil},
+		{"garbage", []byte{0x30, 0x03, 0x02, 0x01}, 1, nil},
+		{"trailing data", append(append([]byte{}, der...), 0), 1, nil},
+		{"outer version", build(func(o *pkcs8, _ *ecPrivateKey) { o.Version = 2 }), 1, nil},
+		{"algorithm", build(func(o *pkcs8, _ *ecPrivateKey) { o.Algo.Algorithm = oidNamedCurveP256 }), 1, nil},
+		{"unknown curve", build(func(o *pkcs8, i *ecPrivateKey) {
+			o.Algo.Parameters = asn1.RawValue{FullBytes: []byte{0x06, 0x03, 0x2a, 0x03, 0x04}}
+			i.Parameters = asn1.RawValue{}
+		}), 2, nil},
+		{"inner version", build(func(_ *pkcs8, i *ecPrivateKey) { i.Version = 2 }), 1, nil},
+		{"inner curve", build(func(_ *pkcs8, i *ecPrivateKey) {
+			i.Parameters = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: p384}
+		}), 1, nil},
+		{"long scalar", build(func(_ *pkcs8, i *ecPrivateKey) { i.PrivateKey = append([]byte{0}, i.PrivateKey...) }), 1, nil},
+		{"zero scalar", build(func(_ *pkcs8, i *ecPrivateKey) { i.PrivateKey = make([]byte, 32) }), 1, nil},
+		{"mismatched public key", build(func(_ *pkcs8, i *ecPrivateKey) { i.PublicKey = other.PublicKey }), 1, ErrKeyMismatch},
+		{"several problems", build(func(o *pkcs8, i *ecPrivateKey) {
+			o.Version = 5
+			i.Version = 0
+			i.PublicKey = other.PublicKey
+		}), 3, ErrKeyMismatch},
+	} {
+		err := ValidatePKCS8(tt.der)
+
+		if tt.errs == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+
+		n := 1
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			n = len(joined.Unwrap())
+		}
+
+		if n != tt.errs {
+			t.Errorf("%s: got %d errors, want %d: %v", tt.name, n, tt.errs, err)
+		}
+
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.is)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"context"
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrVanityNotFound = errors.New("ecgdsa: no key with the requested prefix found")
+
+// GenerateVanityKey generates keys until the compressed encoding of the
+// public point starts with prefix, giving up after maxTries keys. The
+// search runs on all CPUs.
+//
+// The first byte of a compressed point is 0x02 or 0x03, so a prefix that
+// starts with any other byte never matches. Every following byte divides
+// the chance of a match by 256: a one byte prefix takes about 2 tries on
+// average, a two byte prefix about 512 and a three byte prefix about
+// 131072.
+func GenerateVanityKey(curve elliptic.Curve, prefix []byte, rand io.Reader, maxTries int) (*PrivateKey, error) {
+	return GenerateVanityKeyContext(context.Background(), curve, prefix, rand, maxTries)
+}
+
+// GenerateVanityKeyContext is like GenerateVanityKey but gives up with
+// ctx.Err() when ctx is done before a matching key is found.
+func GenerateVanityKeyContext(ctx context.Context, curve elliptic.Curve, prefix []byte, rand io.Reader, maxTries int) (*PrivateKey, error) {
+	if len(prefix) > 0 && prefix[0] != 2 && prefix[0] != 3 {
+		return nil, ErrVanityNotFound
+	}
+
+	if rand == nil {
+		return nil, ErrNilRand
+	}
+
+	rand = &lockedReader{r: rand}
+
+	workers := runtime.NumCPU()
+	if workers > maxTries {
+		workers = maxTries
+	}
+
+	var (
+		tries int64
+		found *PrivateKey
+		err   error
+		once  sync.Once
+		done  = make(chan struct{})
+		wg    sync.WaitGroup
+	)
+
+	finish := func(priv *PrivateKey, e error) {
+		once.Do(func() {
+			found, err = priv, e
+			close(done)
+		})
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done():
+					finish(nil, ctx.Err())
+					return
+				default:
+				}
+
+				if atomic.AddInt64(&tries, 1) > int64(maxTries) {
+					return
+				}
+
+				priv, e := GenerateKey(rand, curve)
+				if e != nil {
+					finish(nil, e)
+					return
+				}
+
+				point := elliptic.MarshalCompressed(curve, priv.X, priv.Y)
+				if bytes.HasPrefix(point, prefix) {
+					finish(priv, nil)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if found == nil && err == nil {
+		err = ErrVanityNotFound
+	}
+
+	return found, err
+}
+
+// lockedReader serializes reads so that a single io.Reader can be shared
+// between goroutines.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.r.Read(p)
+}
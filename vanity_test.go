@@ -0,0 +1,70 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+)
+
+// countCurve counts ScalarBaseMult calls, which GenerateKey makes once
+// per key.
+type countCurve struct {
+	elliptic.Curve
+	calls atomic.Int32
+}
+
+func (c *countCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	c.calls.Add(1)
+	return c.Curve.ScalarBaseMult(k)
+}
+
+func TestGenerateVanityKey(t *testing.T) {
+	for _, prefix := range [][]byte{{2}, {3}} {
+		priv, err := GenerateVanityKey(elliptic.P256(), prefix, rand.Reader, 1000)
+		if err != nil {
+			t.Fatalf("prefix %x: %v", prefix, err)
+		}
+
+		point := elliptic.MarshalCompressed(priv.Curve, priv.X, priv.Y)
+		if !bytes.HasPrefix(point, prefix) {
+			t.Errorf("prefix %x: got key %x", prefix, point)
+		}
+
+		if err := priv.Validate(); err != nil {
+			t.Errorf("prefix %x: %v", prefix, err)
+		}
+	}
+}
+
+func TestGenerateVanityKeyLimit(t *testing.T) {
+	const maxTries = 50
+
+	// Eight zero bytes after the tag match with chance 2⁻⁶⁴ per key.
+	curve := &countCurve{Curve: elliptic.P256()}
+	prefix := []byte{2, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	priv, err := GenerateVanityKey(curve, prefix, rand.Reader, maxTries)
+	if priv != nil || !errors.Is(err, ErrVanityNotFound) {
+		t.Fatalf("got %v, %v, want ErrVanityNotFound", priv, err)
+	}
+
+	if calls := curve.calls.Load(); calls != maxTries {
+		t.Errorf("generated %d keys, want %d", calls, maxTries)
+	}
+
+	// A prefix without a compressed point tag never matches and is
+	// rejected before any key is generated.
+	curve = &countCurve{Curve: elliptic.P256()}
+
+	if _, err := GenerateVanityKey(curve, []byte{4}, rand.Reader, maxTries); !errors.Is(err, ErrVanityNotFound) {
+		t.Errorf("prefix 04: got %v, want ErrVanityNotFound", err)
+	}
+
+	if calls := curve.calls.Load(); calls != 0 {
+		t.Errorf("prefix 04: generated %d keys", calls)
+	}
+}
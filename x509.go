@@ -0,0 +1,556 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// Signature algorithm identifiers of EC-GDSA with a given hash, from BSI
+// TR-03111, section 5.2.1.
+var (
+	oidSignatureECGDSAWithRIPEMD160 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 1}
+	oidSignatureECGDSAWithSHA1      = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 2}
+	oidSignatureECGDSAWithSHA224    = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 3}
+	oidSignatureECGDSAWithSHA256    = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 4}
+	oidSignatureECGDSAWithSHA384    = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 5}
+	oidSignatureECGDSAWithSHA512    = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 5, 4, 6}
+)
+
+var signatureAlgorithms = []struct {
+	hash crypto.Hash
+	oid  asn1.ObjectIdentifier
+}{
+	{crypto.RIPEMD160, oidSignatureECGDSAWithRIPEMD160},
+	{crypto.SHA1, oidSignatureECGDSAWithSHA1},
+	{crypto.SHA224, oidSignatureECGDSAWithSHA224},
+	{crypto.SHA256, oidSignatureECGDSAWithSHA256},
+	{crypto.SHA384, oidSignatureECGDSAWithSHA384},
+	{crypto.SHA512, oidSignatureECGDSAWithSHA512},
+}
+
+var (
+	oidExtensionSubjectKeyID     = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionAuthorityKeyID   = asn1.ObjectIdentifier{2, 5, 29, 35}
+)
+
+// Certificate - Wrapping
+type certificate struct {
+	TBSCertificate     asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+type tbsCertificate struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           validity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+type validity struct {
+	NotBefore, NotAfter time.Time
+}
+
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+type authorityKeyID struct {
+	ID []byte `asn1:"optional,tag:0"`
+}
+
+// signatureAlgorithmFromHash returns the EC-GDSA signature algorithm
+// identifier for h.
+func signatureAlgorithmFromHash(h crypto.Hash) (pkix.AlgorithmIdentifier, error) {
+	if !h.Available() {
+		return pkix.AlgorithmIdentifier{}, ErrHashUnavailable
+	}
+
+	for _, alg := range signatureAlgorithms {
+		if alg.hash == h {
+			return pkix.AlgorithmIdentifier{Algorithm: alg.oid}, nil
+		}
+	}
+
+	return pkix.AlgorithmIdentifier{}, errors.New("ecgdsa: no signature algorithm for hash")
+}
+
+// signTBSCertificate fills in the signature algorithm of tbs, signs it
+// with priv using h and returns the DER certificate.
+func signTBSCertificate(rand io.Reader, tbs *tbsCertificate, priv *PrivateKey, h crypto.Hash) ([]byte, error) {
+	sigAlg, err := signatureAlgorithmFromHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	tbs.SignatureAlgorithm = sigAlg
+
+	tbsDER, err := asn1.Marshal(*tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	d := h.New()
+	d.Write(tbsDER)
+
+	r, s, err := signDigest(rand, priv, d.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := encodeSignature(r, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(certificate{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbsDER},
+		SignatureAlgorithm: sigAlg,
+		SignatureValue:     asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	})
+}
+
+// randomSerialNumber returns a positive serial number of at most 127 bits.
+func randomSerialNumber(rand io.Reader) (*big.Int, error) {
+	if rand == nil {
+		return nil, ErrNilRand
+	}
+
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+
+	b[0] &= 0x7f
+	b[0] |= 0x40
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// CreateSelfSignedCertificate returns a DER encoded X.509 v3 certificate
+// for priv, issued by itself to subject, valid from now for validFor, and
+// signed with EC-GDSA over h (SHA-224, SHA-256, SHA-384, SHA-512, SHA-1 or
+// RIPEMD-160). It is a CA certificate with the key usages digital
+// signature and certificate signing, and carries subject and authority
+// key identifiers computed with SubjectKeyID.
+func CreateSelfSignedCertificate(rand io.Reader, subject pkix.Name, priv *PrivateKey, validFor time.Duration, h crypto.Hash) ([]byte, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if validFor <= 0 {
+		return nil, errors.New("ecgdsa: certificate validity must be positive")
+	}
+
+	spki, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := asn1.Marshal(subject.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := randomSerialNumber(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := SubjectKeyID(&priv.PublicKey)
+
+	ski, err := asn1.Marshal(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	aki, err := asn1.Marshal(authorityKeyID{ID: keyID})
+	if err != nil {
+		return nil, err
+	}
+
+	// digitalSignature (0) and keyCertSign (5)
+	keyUsage, err := asn1.Marshal(asn1.BitString{Bytes: []byte{0x84}, BitLength: 6})
+	if err != nil {
+		return nil, err
+	}
+
+	constraints, err := asn1.Marshal(basicConstraints{IsCA: true, MaxPathLen: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := time.Now().UTC().Truncate(time.Second)
+
+	tbs := &tbsCertificate{
+		Version:      2,
+		SerialNumber: serial,
+		Issuer:       asn1.RawValue{FullBytes: name},
+		Validity:     validity{NotBefore: notBefore, NotAfter: notBefore.Add(validFor)},
+		Subject:      asn1.RawValue{FullBytes: name},
+		PublicKey:    asn1.RawValue{FullBytes: spki},
+		Extensions: []pkix.Extension{
+			{Id: oidExtensionKeyUsage, Critical: true, Value: keyUsage},
+			{Id: oidExtensionBasicConstraints, Critical: true, Value: constraints},
+			{Id: oidExtensionSubjectKeyID, Value: ski},
+			{Id: oidExtensionAuthorityKeyID, Value: aki},
+		},
+	}
+
+	return signTBSCertificate(rand, tbs, priv, h)
+}
+
+// CreateCertificate returns a DER encoded X.509 v3 certificate for pub,
+// issued by parent and signed with EC-GDSA by priv. Pass the same value as
+// template and parent for a self-signed certificate.
+//
+// From template it takes SerialNumber (random if nil), Subject or
+// RawSubject, NotBefore, NotAfter, KeyUsage, the basic constraints
+// (BasicConstraintsValid, IsCA, MaxPathLen, MaxPathLenZero),
+// SubjectKeyId (computed with SubjectKeyID for CA certificates if empty)
+// and ExtraExtensions; other extensions such as subject alternative names
+// must be supplied through ExtraExtensions. The issuer is parent's
+// RawSubject or Subject and, unless the certificate is self-signed,
+// parent's SubjectKeyId becomes the authority key identifier.
+//
+// The hash follows template.SignatureAlgorithm when it is one of the
+// ECDSA algorithms (ECDSAWithSHA1, ECDSAWithSHA256, ECDSAWithSHA384,
+// ECDSAWithSHA512), which only select the hash here; otherwise it is
+// chosen from the security level of priv's curve. The signature algorithm
+// in the certificate is the matching ecgdsa-with-* identifier.
+func CreateCertificate(rand io.Reader, template, parent *x509.Certificate, pub *PublicKey, priv *PrivateKey) ([]byte, error) {
+	if template == nil || parent == nil {
+		return nil, errors.New("ecgdsa: nil certificate template or parent")
+	}
+
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, ErrParametersNotSetUp
+	}
+
+	if len(parent.RawSubjectPublicKeyInfo) > 0 {
+		parentPub, err := ParsePublicKey(parent.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		if !parentPub.Equal(&priv.PublicKey) {
+			return nil, ErrKeyMismatch
+		}
+	}
+
+	h := certificateHash(template.SignatureAlgorithm, priv)
+
+	spki, err := MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := certificateName(template)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := certificateName(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	serial := template.SerialNumber
+	if serial == nil {
+		if serial, err = randomSerialNumber(rand); err != nil {
+			return nil, err
+		}
+	}
+
+	extensions, err := certificateExtensions(template, parent, pub, bytes.Equal(subject, issuer))
+	if err != nil {
+		return nil, err
+	}
+
+	tbs := &tbsCertificate{
+		Version:      2,
+		SerialNumber: serial,
+		Issuer:       asn1.RawValue{FullBytes: issuer},
+		Validity:     validity{NotBefore: template.NotBefore.UTC(), NotAfter: template.NotAfter.UTC()},
+		Subject:      asn1.RawValue{FullBytes: subject},
+		PublicKey:    asn1.RawValue{FullBytes: spki},
+		Extensions:   extensions,
+	}
+
+	return signTBSCertificate(rand, tbs, priv, h)
+}
+
+// certificateHash returns the hash selected by alg, or the one matching
+// the security level of priv's curve.
+func certificateHash(alg x509.SignatureAlgorithm, priv *PrivateKey) crypto.Hash {
+	switch alg {
+	case x509.ECDSAWithSHA1:
+		return crypto.SHA1
+	case x509.ECDSAWithSHA256:
+		return crypto.SHA256
+	case x509.ECDSAWithSHA384:
+		return crypto.SHA384
+	case x509.ECDSAWithSHA512:
+		return crypto.SHA512
+	}
+
+	switch level := SecurityLevel(priv.Curve); {
+	case level <= 128:
+		return crypto.SHA256
+	case level <= 192:
+		return crypto.SHA384
+	default:
+		return crypto.SHA512
+	}
+}
+
+// certificateName returns the DER encoded subject of cert.
+func certificateName(cert *x509.Certificate) ([]byte, error) {
+	if len(cert.RawSubject) > 0 {
+		return cert.RawSubject, nil
+	}
+
+	return asn1.Marshal(cert.Subject.ToRDNSequence())
+}
+
+// certificateExtensions builds the extensions CreateCertificate supports.
+func certificateExtensions(template, parent *x509.Certificate, pub *PublicKey, selfSigned bool) ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+
+	if template.KeyUsage != 0 {
+		value, err := asn1.Marshal(keyUsageBitString(template.KeyUsage))
+		if err != nil {
+			return nil, err
+		}
+
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value})
+	}
+
+	if template.BasicConstraintsValid {
+		maxPathLen := template.MaxPathLen
+		if maxPathLen == 0 && !template.MaxPathLenZero {
+			maxPathLen = -1
+		}
+
+		value, err := asn1.Marshal(basicConstraints{IsCA: template.IsCA, MaxPathLen: maxPathLen})
+		if err != nil {
+			return nil, err
+		}
+
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionBasicConstraints, Critical: true, Value: value})
+	}
+
+	keyID := template.SubjectKeyId
+	if len(keyID) == 0 && template.IsCA {
+		keyID = SubjectKeyID(pub)
+	}
+
+	if len(keyID) > 0 {
+		value, err := asn1.Marshal(keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionSubjectKeyID, Value: value})
+	}
+
+	if !selfSigned && len(parent.SubjectKeyId) > 0 {
+		value, err := asn1.Marshal(authorityKeyID{ID: parent.SubjectKeyId})
+		if err != nil {
+			return nil, err
+		}
+
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionAuthorityKeyID, Value: value})
+	}
+
+	return append(extensions, template.ExtraExtensions...), nil
+}
+
+// keyUsageBitString encodes ku as the KeyUsage BIT STRING, where bit i of
+// ku is named bit i, counted from the most significant bit.
+func keyUsageBitString(ku x509.KeyUsage) asn1.BitString {
+	var b [2]byte
+	for i := 0; i < 16; i++ {
+		if ku&(1<<i) != 0 {
+			b[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	bitString := b[:1]
+	if b[1] != 0 {
+		bitString = b[:]
+	}
+
+	bitLength := 8 * len(bitString)
+	for bitLength > 0 && bitString[(bitLength-1)/8]&(0x80>>((bitLength-1)%8)) == 0 {
+		bitLength--
+	}
+
+	return asn1.BitString{Bytes: bitString, BitLength: bitLength}
+}
+
+// CheckCertificateSignature verifies that cert is signed with EC-GDSA by
+// its own public key, as a self-signed certificate is. Certificates issued
+// by another key are checked with VerifyWithChainOpts.
+func CheckCertificateSignature(cert *x509.Certificate) error {
+	pub, err := PublicKeyFromCertificate(cert)
+	if err != nil {
+		return err
+	}
+
+	return checkCertificateSignature(cert, pub)
+}
+
+// PublicKeyFromCertificate returns the EC-GDSA public key of cert.
+func PublicKeyFromCertificate(cert *x509.Certificate) (*PublicKey, error) {
+	if cert == nil {
+		return nil, errors.New("ecgdsa: nil certificate")
+	}
+
+	return ParsePublicKey(cert.RawSubjectPublicKeyInfo)
+}
+
+// hashFromSignatureAlgorithm returns the hash of an EC-GDSA signature
+// algorithm identifier.
+func hashFromSignatureAlgorithm(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	for _, alg := range signatureAlgorithms {
+		if alg.oid.Equal(oid) {
+			if !alg.hash.Available() {
+				return 0, ErrHashUnavailable
+			}
+
+			return alg.hash, nil
+		}
+	}
+
+	return 0, fmt.Errorf("ecgdsa: signature algorithm %s is not EC-GDSA", oid)
+}
+
+// checkCertificateSignature verifies that cert is signed with EC-GDSA by
+// the holder of pub.
+func checkCertificateSignature(cert *x509.Certificate, pub *PublicKey) error {
+	var c certificate
+	if _, err := asn1.Unmarshal(cert.Raw, &c); err != nil {
+		return err
+	}
+
+	h, err := hashFromSignatureAlgorithm(c.SignatureAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	r, s, err := parseSignature(cert.Signature)
+	if err != nil {
+		return err
+	}
+
+	d := h.New()
+	d.Write(cert.RawTBSCertificate)
+
+	if !verifyDigest(pub, d.Sum(nil), r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ChainOpts selects the checks VerifyWithChainOpts applies to the chain.
+type ChainOpts struct {
+	// CheckSignatures verifies that every certificate is signed with
+	// EC-GDSA by the next one, and the last one by itself if it is self
+	// issued.
+	CheckSignatures bool
+
+	// CheckValidity checks that every certificate is within its validity
+	// period at CurrentTime and that every issuer is a CA.
+	CheckValidity bool
+
+	// CurrentTime is the time for CheckValidity. The zero value means
+	// time.Now().
+	CurrentTime time.Time
+}
+
+// VerifyWithChain verifies the ASN.1 encoded signature sig of the hash
+// value hash with the EC-GDSA key of the leaf certificate chain[0]. The
+// rest of the chain is not checked; see VerifyWithChainOpts.
+func VerifyWithChain(chain []*x509.Certificate, hash, sig []byte) (bool, error) {
+	return VerifyWithChainOpts(chain, hash, sig, nil)
+}
+
+// VerifyWithChainOpts is like VerifyWithChain and additionally checks
+// the chain as selected by opts. chain is ordered from the leaf to the
+// root. A failed chain check is returned as an error; a signature that
+// does not verify is reported as false with a nil error.
+func VerifyWithChainOpts(chain []*x509.Certificate, hash, sig []byte, opts *ChainOpts) (bool, error) {
+	if len(chain) == 0 {
+		return false, errors.New("ecgdsa: empty certificate chain")
+	}
+
+	if opts == nil {
+		opts = &ChainOpts{}
+	}
+
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	for i, cert := range chain {
+		if opts.CheckValidity {
+			if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+				return false, fmt.Errorf("ecgdsa: certificate %d is not valid at %s", i, now.Format(time.RFC3339))
+			}
+
+			if i > 0 && (!cert.BasicConstraintsValid || !cert.IsCA) {
+				return false, fmt.Errorf("ecgdsa: certificate %d is not a CA", i)
+			}
+		}
+
+		if !opts.CheckSignatures {
+			continue
+		}
+
+		issuer := cert
+		if i+1 < len(chain) {
+			issuer = chain[i+1]
+		} else if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+			continue
+		}
+
+		pub, err := PublicKeyFromCertificate(issuer)
+		if err != nil {
+			return false, err
+		}
+
+		if err := checkCertificateSignature(cert, pub); err != nil {
+			return false, fmt.Errorf("ecgdsa: certificate %d: %w", i, err)
+		}
+	}
+
+	pub, err := PublicKeyFromCertificate(chain[0])
+	if err != nil {
+		return false, err
+	}
+
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		return false, nil
+	}
+
+	return verifyDigest(pub, hash, r, s), nil
+}
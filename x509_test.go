@@ -0,0 +1,348 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateSelfSignedCertificate(t *testing.T) {
+	priv := testKey(t)
+
+	for _, h := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		der, err := CreateSelfSignedCertificate(rand.Reader, pkix.Name{CommonName: "root"}, priv, 24*time.Hour, h)
+		if err != nil {
+			t.Fatalf("%v: %v", h, err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("%v: %v", h, err)
+		}
+
+		if cert.Subject.CommonName != "root" || !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+			t.Errorf("%v: subject %v, issuer %v", h, cert.Subject, cert.Issuer)
+		}
+
+		if !cert.BasicConstraintsValid || !cert.IsCA || cert.KeyUsage != x509.KeyUsageDigitalSignature|x509.KeyUsageCertSign {
+			t.Errorf("%v: CA %v, key usage %v", h, cert.IsCA, cert.KeyUsage)
+		}
+
+		keyID := SubjectKeyID(&priv.PublicKey)
+		if !bytes.Equal(cert.SubjectKeyId, keyID) || !bytes.Equal(cert.AuthorityKeyId, keyID) {
+			t.Errorf("%v: key identifiers %x and %x", h, cert.SubjectKeyId, cert.AuthorityKeyId)
+		}
+
+		if cert.NotAfter.Sub(cert.NotBefore) != 24*time.Hour {
+			t.Errorf("%v: valid from %v to %v", h, cert.NotBefore, cert.NotAfter)
+		}
+
+		pub, err := PublicKeyFromCertificate(cert)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !pub.Equal(&priv.PublicKey) {
+			t.Errorf("%v: certificate holds another key", h)
+		}
+
+		if err := CheckCertificateSignature(cert); err != nil {
+			t.Errorf("%v: %v", h, err)
+		}
+
+		cert.Signature = append([]byte{}, cert.Signature...)
+		cert.Signature[len(cert.Signature)-1] ^= 1
+
+		if err := CheckCertificateSignature(cert); err == nil {
+			t.Errorf("%v: tampered signature accepted", h)
+		}
+	}
+
+	if _, err := CreateSelfSignedCertificate(rand.Reader, pkix.Name{CommonName: "root"}, priv, 0, crypto.SHA256); err == nil {
+		t.Error("zero validity accepted")
+	}
+}
+
+// testChain returns a self-signed CA certificate and a leaf certificate it
+// issued to leaf.
+func testChain(t *testing.T, ca, leaf *PrivateKey, isCA bool) (*x509.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	caDER, err := CreateSelfSignedCertificate(rand.Reader, pkix.Name{CommonName: "root"}, ca, 24*time.Hour, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "leaf"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	leafDER, err := CreateCertificate(rand.Reader, template, caCert, &leaf.PublicKey, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafCert, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return caCert, leafCert
+}
+
+func TestVerifyWithChainOpts(t *testing.T) {
+	ca, leaf, other := testKey(t), testKey(t), testKey(t)
+	caCert, leafCert := testChain(t, ca, leaf, false)
+	otherCA, _ := testChain(t, other, leaf, false)
+	_, grandchild := testChain(t, leaf, other, false)
+
+	if !bytes.Equal(leafCert.RawIssuer, caCert.RawSubject) || !bytes.Equal(leafCert.AuthorityKeyId, caCert.SubjectKeyId) {
+		t.Error("leaf issuer does not name the CA")
+	}
+
+	hash := sha256.Sum256([]byte("message"))
+
+	sig, err := SignASN1(rand.Reader, leaf, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caSig, err := SignASN1(rand.Reader, ca, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := &ChainOpts{CheckSignatures: true, CheckValidity: true}
+
+	for _, tt := range []struct {
+		name  string
+		chain []*x509.Certificate
+		sig   []byte
+		opts  *ChainOpts
+		ok    bool
+		err   bool
+	}{
+		{"valid", []*x509.Certificate{leafCert, caCert}, sig, all, true, false},
+		{"no checks", []*x509.Certificate{leafCert, otherCA}, sig, nil, true, false},
+		{"signature of the CA", []*x509.Certificate{leafCert, caCert}, caSig, all, false, false},
+		{"wrong CA", []*x509.Certificate{leafCert, otherCA}, sig, all, false, true},
+		{"expired", []*x509.Certificate{leafCert, caCert}, sig, &ChainOpts{CheckValidity: true, CurrentTime: time.Now().Add(2 * time.Hour)}, false, true},
+		{"issuer not a CA", []*x509.Certificate{grandchild, leafCert, caCert}, caSig, &ChainOpts{CheckValidity: true}, false, true},
+		{"empty", nil, sig, all, false, true},
+	} {
+		ok, err := VerifyWithChainOpts(tt.chain, hash[:], tt.sig, tt.opts)
+		if ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("%s: got %v, %v", tt.name, ok, err)
+		}
+	}
+
+	// The grandchild is signed by the leaf key, so its signatures check
+	// out even though the leaf is not a CA.
+	ok, err := VerifyWithChainOpts([]*x509.Certificate{grandchild, leafCert, caCert}, hash[:], sig, &ChainOpts{CheckSignatures: true})
+	if ok || err != nil {
+		t.Errorf("grandchild chain: got %v, %v", ok, err)
+	}
+
+	if _, err := VerifyWithChainOpts([]*x509.Certificate{leafCert, otherCA}, hash[:], sig, all); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong CA: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyWithChain(t *testing.T) {
+	ca, leaf := testKey(t), testKey(t)
+	caCert, leafCert := testChain(t, ca, leaf, false)
+	chain := []*x509.Certificate{leafCert, caCert}
+
+	pub, err := PublicKeyFromCertificate(leafCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pub.Equal(&leaf.PublicKey) {
+		t.Error("PublicKeyFromCertificate returned another key")
+	}
+
+	if _, err := PublicKeyFromCertificate(nil); err == nil {
+		t.Error("nil certificate accepted")
+	}
+
+	// A certificate with an ECDSA key has no EC-GDSA public key.
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: leafCert.SerialNumber,
+		Subject:      pkix.Name{CommonName: "ecdsa"},
+		NotBefore:    leafCert.NotBefore,
+		NotAfter:     leafCert.NotAfter,
+	}, &x509.Certificate{Subject: pkix.Name{CommonName: "ecdsa"}}, &ecdsaKey.PublicKey, ecdsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaCert, err := x509.ParseCertificate(ecdsaDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := PublicKeyFromCertificate(ecdsaCert); err == nil {
+		t.Error("ECDSA certificate accepted")
+	}
+
+	hash := sha256.Sum256([]byte("message"))
+
+	sig, err := SignASN1(rand.Reader, leaf, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caSig, err := SignASN1(rand.Reader, ca, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := sha256.Sum256([]byte("other"))
+
+	for _, tt := range []struct {
+		name  string
+		chain []*x509.Certificate
+		hash  []byte
+		sig   []byte
+		ok    bool
+		err   bool
+	}{
+		{"valid", chain, hash[:], sig, true, false},
+		{"leaf only", chain[:1], hash[:], sig, true, false},
+		{"other hash", chain, other[:], sig, false, false},
+		{"signature of the CA", chain, hash[:], caSig, false, false},
+		{"ECDSA leaf", []*x509.Certificate{ecdsaCert, caCert}, hash[:], sig, false, true},
+		{"empty", nil, hash[:], sig, false, true},
+	} {
+		ok, err := VerifyWithChain(tt.chain, tt.hash, tt.sig)
+		if ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("%s: got %v, %v", tt.name, ok, err)
+		}
+	}
+}
+
+func TestCreateCertificate(t *testing.T) {
+	p384, err := GenerateKey(rand.Reader, elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p521, err := GenerateKey(rand.Reader, elliptic.P521())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p256 := testKey(t)
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name string
+		priv *PrivateKey
+		alg  x509.SignatureAlgorithm
+		want asn1.ObjectIdentifier
+	}{
+		{"SHA-1", p256, x509.ECDSAWithSHA1, oidSignatureECGDSAWithSHA1},
+		{"SHA-256", p256, x509.ECDSAWithSHA256, oidSignatureECGDSAWithSHA256},
+		{"SHA-384", p256, x509.ECDSAWithSHA384, oidSignatureECGDSAWithSHA384},
+		{"SHA-512", p384, x509.ECDSAWithSHA512, oidSignatureECGDSAWithSHA512},
+		{"default P-256", p256, x509.UnknownSignatureAlgorithm, oidSignatureECGDSAWithSHA256},
+		{"default P-384", p384, x509.UnknownSignatureAlgorithm, oidSignatureECGDSAWithSHA384},
+		{"default P-521", p521, x509.UnknownSignatureAlgorithm, oidSignatureECGDSAWithSHA512},
+	} {
+		template := &x509.Certificate{
+			Subject:            pkix.Name{CommonName: tt.name},
+			NotBefore:          now,
+			NotAfter:           now.Add(time.Hour),
+			SignatureAlgorithm: tt.alg,
+		}
+
+		der, err := CreateCertificate(rand.Reader, template, template, &tt.priv.PublicKey, tt.priv)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		// The outer algorithm and the one inside the TBSCertificate both
+		// name the EC-GDSA OID for the hash.
+		var c certificate
+		if _, err := asn1.Unmarshal(der, &c); err != nil {
+			t.Fatal(err)
+		}
+
+		if !c.SignatureAlgorithm.Algorithm.Equal(tt.want) {
+			t.Errorf("%s: signature algorithm %s, want %s", tt.name, c.SignatureAlgorithm.Algorithm, tt.want)
+		}
+
+		wantDER, err := asn1.Marshal(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Contains(cert.RawTBSCertificate, wantDER) {
+			t.Errorf("%s: TBSCertificate does not name %s", tt.name, tt.want)
+		}
+
+		if cert.SerialNumber == nil || cert.SerialNumber.Sign() <= 0 {
+			t.Errorf("%s: serial number %v", tt.name, cert.SerialNumber)
+		}
+
+		if err := CheckCertificateSignature(cert); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+
+	// A certificate issued to another key is checked against the issuer.
+	caCert, leafCert := testChain(t, p256, p384, false)
+
+	if err := checkCertificateSignature(leafCert, &p256.PublicKey); err != nil {
+		t.Errorf("issued certificate: %v", err)
+	}
+
+	if err := CheckCertificateSignature(leafCert); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("issued certificate against its own key: got %v", err)
+	}
+
+	template := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}, NotBefore: now, NotAfter: now.Add(time.Hour)}
+
+	if _, err := CreateCertificate(rand.Reader, template, caCert, &p384.PublicKey, p521); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("wrong issuer key: got %v, want ErrKeyMismatch", err)
+	}
+
+	if _, err := CreateCertificate(rand.Reader, nil, caCert, &p384.PublicKey, p256); err == nil {
+		t.Error("nil template accepted")
+	}
+
+	if _, err := CreateCertificate(rand.Reader, template, caCert, &PublicKey{}, p256); err == nil {
+		t.Error("empty subject key accepted")
+	}
+}
@@ -0,0 +1,89 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+)
+
+var pemDashes = []byte("-----")
+
+// DecodePEMKeysBytes is like DecodePEMKeys for input that is already in
+// memory, such as a memory-mapped trust bundle. data is never copied or
+// written to: the armor is located by slicing data, and the base64 body of
+// every block is decoded into one scratch buffer that is reused from block
+// to block. The parsed keys do not refer to data or to the scratch buffer.
+//
+// data may be read-only, but the caller must not modify it until
+// DecodePEMKeysBytes returns. Blocks with headers, such as legacy
+// encrypted PEM, are skipped like other non-key blocks.
+func DecodePEMKeysBytes(data []byte, fn func(key interface{}) error) error {
+	var scratch []byte
+
+	for index := 0; ; index++ {
+		start := bytes.Index(data, pemBegin)
+		if start < 0 {
+			return nil
+		}
+
+		data = data[start+len(pemBegin):]
+
+		typeEnd := bytes.Index(data, pemDashes)
+		if typeEnd < 0 {
+			return nil
+		}
+
+		typ := data[:typeEnd]
+		if bytes.IndexByte(typ, '\n') >= 0 {
+			continue
+		}
+
+		data = data[typeEnd+len(pemDashes):]
+
+		end := bytes.Index(data, pemEnd)
+		if end < 0 {
+			return nil
+		}
+
+		body := data[:end]
+		data = data[end+len(pemEnd):]
+
+		if !bytes.HasPrefix(data, typ) || !bytes.HasPrefix(data[len(typ):], pemDashes) ||
+			bytes.IndexByte(body, ':') >= 0 {
+			continue
+		}
+
+		var parse func([]byte) (interface{}, error)
+
+		switch string(typ) {
+		case pemPrivateKeyType:
+			parse = func(der []byte) (interface{}, error) { return ParsePrivateKey(der) }
+		case pemECPrivateKeyType:
+			parse = func(der []byte) (interface{}, error) { return ParseSEC1PrivateKey(der) }
+		case pemPublicKeyType:
+			parse = func(der []byte) (interface{}, error) { return ParsePublicKey(der) }
+		default:
+			continue
+		}
+
+		body = bytes.TrimSpace(body)
+
+		if n := base64.StdEncoding.DecodedLen(len(body)); cap(scratch) < n {
+			scratch = make([]byte, n)
+		}
+
+		n, err := base64.StdEncoding.Decode(scratch[:cap(scratch)], body)
+		if err != nil {
+			continue
+		}
+
+		key, err := parse(scratch[:n])
+		if err != nil {
+			return fmt.Errorf("ecgdsa: PEM block %d: %w", index, err)
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+}
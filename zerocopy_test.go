@@ -0,0 +1,101 @@
+package ecgdsa
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestDecodePEMKeysBytes(t *testing.T) {
+	data := bytes.Repeat(pemBundleUnit(t), 8)
+	orig := append([]byte{}, data...)
+
+	var want, got []interface{}
+
+	collect := func(keys *[]interface{}) func(interface{}) error {
+		return func(key interface{}) error {
+			*keys = append(*keys, key)
+			return nil
+		}
+	}
+
+	if err := DecodePEMKeys(bytes.NewReader(data), collect(&want)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DecodePEMKeysBytes(data, collect(&got)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, orig) {
+		t.Fatal("input modified")
+	}
+
+	if len(got) != len(want) || len(got) != 8*3 {
+		t.Fatalf("got %d keys, want %d from DecodePEMKeys and %d", len(got), len(want), 8*3)
+	}
+
+	for i := range got {
+		switch w := want[i].(type) {
+		case *PrivateKey:
+			if g, ok := got[i].(*PrivateKey); !ok || !g.Equal(w) {
+				t.Errorf("key %d differs", i)
+			}
+		case *PublicKey:
+			if g, ok := got[i].(*PublicKey); !ok || !g.Equal(w) {
+				t.Errorf("key %d differs", i)
+			}
+		}
+	}
+
+	// The keys must not refer to the input.
+	for i := range data {
+		data[i] = 0
+	}
+
+	if priv := got[0].(*PrivateKey); !priv.Equal(want[0].(*PrivateKey)) {
+		t.Error("key changed with the input")
+	}
+}
+
+// TestDecodePEMKeysBytesAllocs checks that decoding from memory allocates
+// less than decoding the same bundle through a reader.
+func TestDecodePEMKeysBytesAllocs(t *testing.T) {
+	data := pemPublicKeyBundle(t, 32)
+	discard := func(interface{}) error { return nil }
+
+	fromReader := testing.AllocsPerRun(10, func() {
+		DecodePEMKeys(bytes.NewReader(data), discard)
+	})
+
+	fromBytes := testing.AllocsPerRun(10, func() {
+		DecodePEMKeysBytes(data, discard)
+	})
+
+	if fromBytes >= fromReader {
+		t.Errorf("DecodePEMKeysBytes made %.0f allocations, DecodePEMKeys %.0f", fromBytes, fromReader)
+	}
+}
+
+// pemPublicKeyBundle returns n public keys as PEM.
+func pemPublicKeyBundle(t testing.TB, n int) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		priv, err := GenerateKey(rand.Reader, elliptic.P256())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pubPEM, err := EncodePublicKeyPEM(&priv.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b.Write(pubPEM)
+	}
+
+	return b.Bytes()
+}